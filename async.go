@@ -0,0 +1,220 @@
+package log
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// An OverflowPolicy decides what an asynchronous Logger does with a new
+// entry when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the logging goroutine wait for room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the new entry.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued entry to make room.
+	OverflowDropOldest
+)
+
+// asyncWriter is a bounded queue of formatted entries written by a
+// background goroutine. Each entry remembers the writer that was current
+// when it was logged.
+type asyncWriter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond // signalled whenever any of the following change
+	ring   []asyncEntry
+	head   int // index of the oldest entry
+	n      int // number of queued entries
+	busy   bool
+	closed bool
+	policy OverflowPolicy
+	err    error // first write error since the last flush
+
+	dropped int        // entries dropped since the last notice was written
+	notice  asyncEntry // pending report of dropped entries, if dropped > 0
+
+	done chan struct{}
+}
+
+type asyncEntry struct {
+	w io.Writer
+	b []byte
+}
+
+func newAsyncWriter(size int, policy OverflowPolicy) *asyncWriter {
+	a := &asyncWriter{
+		ring:   make([]asyncEntry, size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	a.cond = sync.NewCond(&a.mu)
+	go a.run()
+	return a
+}
+
+// enqueue copies b into the queue for writing to w. When entries are
+// dropped, l formats the report of how many; l.mu must be held.
+func (a *asyncWriter) enqueue(w io.Writer, b []byte, l *Logger) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.n == len(a.ring) {
+		switch a.policy {
+		case OverflowDropNewest:
+			a.drop(w, l)
+			return
+		case OverflowDropOldest:
+			a.head = (a.head + 1) % len(a.ring)
+			a.n--
+			a.drop(w, l)
+		default:
+			for a.n == len(a.ring) {
+				a.cond.Wait()
+			}
+		}
+	}
+	e := &a.ring[(a.head+a.n)%len(a.ring)]
+	e.w = w
+	e.b = append(e.b[:0], b...)
+	a.n++
+	a.cond.Broadcast()
+}
+
+func (a *asyncWriter) drop(w io.Writer, l *Logger) {
+	a.dropped++
+	a.notice.w = w
+	a.notice.b = a.notice.b[:0]
+	l.dropNotice(&a.notice.b, a.dropped)
+	a.cond.Broadcast()
+}
+
+// run writes queued entries until the writer is closed and drained. A
+// pending notice of dropped entries is written ahead of the next entry.
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	var spare []byte
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		for a.n == 0 && a.dropped == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		var e *asyncEntry
+		switch {
+		case a.dropped > 0:
+			e = &a.notice
+			a.dropped = 0
+		case a.n > 0:
+			e = &a.ring[a.head]
+			a.head = (a.head + 1) % len(a.ring)
+			a.n--
+		default:
+			return // closed and drained.
+		}
+		w, b := e.w, e.b
+		e.w, e.b = nil, spare[:0]
+		a.busy = true
+		a.cond.Broadcast()
+		a.mu.Unlock()
+		_, err := w.Write(b)
+		a.mu.Lock()
+		a.busy = false
+		if err != nil && a.err == nil {
+			a.err = err
+		}
+		spare = b
+		a.cond.Broadcast()
+	}
+}
+
+// flush waits until every entry queued so far has been written and returns
+// the first write error since the previous flush.
+func (a *asyncWriter) flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.n > 0 || a.dropped > 0 || a.busy {
+		a.cond.Wait()
+	}
+	err := a.err
+	a.err = nil
+	return err
+}
+
+// close drains the queue and stops the background goroutine.
+func (a *asyncWriter) close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+	<-a.done
+	return a.flush()
+}
+
+// SetAsync switches the logger, and every Logger derived from it with
+// With, to asynchronous writing: entries are formatted by the logging
+// goroutine and queued, up to size of them, for a background goroutine to
+// write. policy decides what happens when the queue is full; with the drop
+// policies a warning with the number of dropped entries is written ahead
+// of the next entry. Output then reports no write errors; they are
+// returned by Flush and Close instead.
+//
+// A size of zero or less, like Close, drains the queue and returns to
+// synchronous writing.
+func (l *Logger) SetAsync(size int, policy OverflowPolicy) {
+	var a *asyncWriter
+	if size > 0 {
+		a = newAsyncWriter(size, policy)
+	}
+	l.mu.Lock()
+	old := l.async
+	l.async = a
+	l.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+}
+
+// Flush waits until every entry queued by an asynchronous logger has been
+// written and returns the first write error since the previous Flush. It
+// does nothing for a synchronous logger.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	a := l.async
+	l.mu.Unlock()
+	if a == nil {
+		return nil
+	}
+	return a.flush()
+}
+
+// Close drains the queue of an asynchronous logger, stops its background
+// goroutine and returns the logger to synchronous writing. It returns the
+// first write error since the previous Flush.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	a := l.async
+	l.async = nil
+	l.mu.Unlock()
+	if a == nil {
+		return nil
+	}
+	return a.close()
+}
+
+// dropNotice formats the report of n dropped entries with the logger's
+// formatter. l.mu must be held.
+func (l *Logger) dropNotice(buf *[]byte, n int) {
+	r := &Record{
+		Time:       time.Now(),
+		TimeLayout: l.layout,
+		Flags:      l.flag &^ (Llongfile | Lshortfile),
+		Prefix:     l.prefix,
+		Level:      WarnLevel,
+		Message:    "log: dropped " + strconv.Itoa(n) + " entries",
+	}
+	l.formatterLocked().Format(buf, r)
+	*buf = append(*buf, '\n')
+}
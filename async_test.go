@@ -0,0 +1,87 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gateWriter blocks its first Write until release is closed.
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.started)
+		<-w.release
+	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsync(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	l.SetAsync(4, OverflowBlock)
+	var want []string
+	for i := 0; i < 100; i++ {
+		l.Println(i)
+		want = append(want, fmt.Sprint(i))
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("got %q", got)
+	}
+	l.Close()
+	l.Print("sync")
+	if !strings.HasSuffix(b.String(), "99\nsync\n") {
+		t.Errorf("not synchronous after Close: %q", b.String())
+	}
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{OverflowDropNewest, "0\nWARN log: dropped 3 entries\n1\n2\n"},
+		{OverflowDropOldest, "0\nWARN log: dropped 3 entries\n4\n5\n"},
+	}
+	for _, tt := range tests {
+		w := newGateWriter()
+		l := New(w, "", 0)
+		l.SetAsync(2, tt.policy)
+		l.Println(0)
+		<-w.started
+		for i := 1; i <= 5; i++ {
+			l.Println(i)
+		}
+		close(w.release)
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := w.String(); got != tt.want {
+			t.Errorf("policy %d: got %q; want %q", tt.policy, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// frame is the caller information resolved for a program counter.
+type frame struct {
+	file string
+	line int
+	fn   string
+}
+
+// frames caches resolved frames by program counter, since
+// runtime.CallersFrames is too slow to call for every entry.
+var frames sync.Map // map[uintptr]frame
+
+// caller returns the file, line and fully qualified function name of the
+// caller skip frames up, counted as by runtime.Caller from the caller of
+// caller. Inlined frames are reported as the function written in the
+// source, not the one they were inlined into.
+func caller(skip int) (file string, line int, fn string) {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 { // +2 for runtime.Callers and this frame.
+		return "???", 0, ""
+	}
+	if f, ok := frames.Load(pcs[0]); ok {
+		f := f.(frame)
+		return f.file, f.line, f.fn
+	}
+	rf, _ := runtime.CallersFrames(pcs[:]).Next()
+	f := frame{file: rf.File, line: rf.Line, fn: rf.Function}
+	if f.file == "" {
+		f.file = "???"
+	}
+	frames.Store(pcs[0], f)
+	return f.file, f.line, f.fn
+}
+
+// funcName returns the part of the qualified function name fn selected by
+// Lfuncname and Lpackage in flag: the package path, the function name
+// within the package, or both.
+func funcName(fn string, flag int) string {
+	if fn == "" {
+		return ""
+	}
+	// The package path ends at the first dot after the last slash.
+	dot := strings.LastIndexByte(fn, '/') + 1
+	if i := strings.IndexByte(fn[dot:], '.'); i >= 0 {
+		dot += i
+	} else {
+		dot = len(fn)
+	}
+	switch flag & (Lfuncname | Lpackage) {
+	case Lfuncname | Lpackage:
+		return fn
+	case Lfuncname:
+		if dot < len(fn) {
+			return fn[dot+1:]
+		}
+		return fn
+	case Lpackage:
+		return fn[:dot]
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+// inlined is small enough to be inlined into its callers.
+func inlined(l *Logger) { l.Print("hello") }
+
+func TestFuncName(t *testing.T) {
+	tests := []struct {
+		flag int
+		want string
+	}{
+		{Lfuncname, "inlined: hello\n"},
+		{Lpackage, "github.com/cention-sany/log: hello\n"},
+		{Lfuncname | Lpackage, "github.com/cention-sany/log.inlined: hello\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		l := New(&b, "", tt.flag)
+		for i := 0; i < 2; i++ { // the second call hits the cache.
+			b.Reset()
+			inlined(l)
+			if got := b.String(); got != tt.want {
+				t.Errorf("flag %#x: got %q; want %q", tt.flag, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFuncNameClosure(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", Lfuncname)
+	l.Print("direct")
+	func() { l.Print("closure") }()
+	want := "TestFuncNameClosure: direct\nTestFuncNameClosure.func1: closure\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestFuncNameSplit(t *testing.T) {
+	const fn = "a/b.c/d.(*T).run.func1"
+	tests := []struct {
+		flag int
+		want string
+	}{
+		{0, ""},
+		{Lfuncname, "(*T).run.func1"},
+		{Lpackage, "a/b.c/d"},
+		{Lfuncname | Lpackage, fn},
+	}
+	for _, tt := range tests {
+		if got := funcName(fn, tt.flag); got != tt.want {
+			t.Errorf("funcName(%q, %#x) = %q; want %q", fn, tt.flag, got, tt.want)
+		}
+	}
+}
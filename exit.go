@@ -0,0 +1,105 @@
+package log
+
+import "os"
+
+// SetExitFunc sets the function the Fatal methods call to end the process
+// after writing the entry, flushing an asynchronous logger and running the
+// hooks added with OnExit. It defaults to os.Exit; a nil f restores that.
+// If f returns, so does Fatal.
+func (l *Logger) SetExitFunc(f func(code int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exitFunc = f
+}
+
+// SetExitCode sets the code the Fatal methods exit with. It defaults to 1.
+func (l *Logger) SetExitCode(code int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exitCode = code
+}
+
+// OnExit adds a hook for the Fatal methods to run before exiting, e.g. to
+// flush other loggers or close files. Hooks run in the order they were
+// added, after the logger itself has been flushed.
+func (l *Logger) OnExit(hook func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exitHooks = append(l.exitHooks, hook)
+}
+
+// SetPanicFunc sets the function that makes the value the Panic methods
+// pass to panic from the message s logged at file:line. It defaults to
+// passing s itself; a nil f restores that. NewPanicError is one such
+// function.
+func (l *Logger) SetPanicFunc(f func(s, file string, line int) interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.panicFunc = f
+}
+
+// exit flushes the logger, runs its exit hooks and calls its exit function.
+func (l *Logger) exit() {
+	l.Flush()
+	l.mu.Lock()
+	hooks, exit, code := l.exitHooks, l.exitFunc, l.exitCode
+	l.mu.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(code)
+}
+
+// panicValue returns the value to panic with for the message s, logged by
+// the caller calldepth frames up, counted as by runtime.Caller from the
+// caller of panicValue.
+func (l *Logger) panicValue(calldepth int, s string) interface{} {
+	l.mu.Lock()
+	f := l.panicFunc
+	l.mu.Unlock()
+	if f == nil {
+		return s
+	}
+	file, line, _ := caller(calldepth)
+	return f(s, file, line)
+}
+
+// A PanicError is a panic value that carries the message and the caller
+// of a Panic entry.
+type PanicError struct {
+	Message string
+	File    string
+	Line    int
+}
+
+func (e *PanicError) Error() string {
+	return e.Message
+}
+
+// NewPanicError returns a *PanicError. It can be passed to SetPanicFunc.
+func NewPanicError(s, file string, line int) interface{} {
+	return &PanicError{Message: s, File: file, Line: line}
+}
+
+// SetExitFunc sets the exit function of the standard logger.
+func SetExitFunc(f func(code int)) {
+	std.SetExitFunc(f)
+}
+
+// SetExitCode sets the exit code of the standard logger.
+func SetExitCode(code int) {
+	std.SetExitCode(code)
+}
+
+// OnExit adds an exit hook to the standard logger.
+func OnExit(hook func()) {
+	std.OnExit(hook)
+}
+
+// SetPanicFunc sets the panic function of the standard logger.
+func SetPanicFunc(f func(s, file string, line int) interface{}) {
+	std.SetPanicFunc(f)
+}
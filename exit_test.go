@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFatalExitFunc(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	l.SetAsync(1, OverflowBlock)
+	defer l.Close()
+	var calls []string
+	l.OnExit(func() { calls = append(calls, "hook1:"+b.String()) })
+	l.OnExit(func() { calls = append(calls, "hook2") })
+	code := -1
+	l.SetExitFunc(func(c int) { code = c })
+	l.SetExitCode(3)
+	l.Fatalf("fatal %d", 1)
+	if code != 3 {
+		t.Errorf("exit code: got %d; want 3", code)
+	}
+	want := []string{"hook1:fatal 1\n", "hook2"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("hooks: got %q; want %q", calls, want)
+	}
+}
+
+func TestPanicFunc(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	l.SetPanicFunc(NewPanicError)
+	defer func() {
+		e, ok := recover().(*PanicError)
+		if !ok {
+			t.Fatalf("panic value is not a *PanicError")
+		}
+		if e.Error() != "boom" || !strings.HasSuffix(e.File, "exit_test.go") || e.Line == 0 {
+			t.Errorf("unexpected panic value %+v", e)
+		}
+	}()
+	l.Panic("boom")
+}
+
+func TestPanicDefault(t *testing.T) {
+	l := New(new(bytes.Buffer), "", 0)
+	defer func() {
+		if v := recover(); v != "boom\n" {
+			t.Errorf("got panic value %q; want %q", v, "boom\n")
+		}
+	}()
+	l.Panicln("boom")
+}
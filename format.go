@@ -0,0 +1,112 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// A Formatter lays out log entries. Format appends the entry r to buf,
+// without a trailing newline; the Logger terminates the line. Format is
+// called with the Logger's mutex held and must not retain r or its fields.
+type Formatter interface {
+	Format(buf *[]byte, r *Record)
+}
+
+// A Record is a single log entry handed to a Formatter.
+type Record struct {
+	Time       time.Time // when the entry was logged, in the local time zone
+	TimeLayout string    // time layout of the Logger; see SetTimeLayout
+	Flags      int       // output flags of the Logger
+	Prefix     string    // prefix of the Logger
+	File       string    // caller file name; set only if Llongfile, Lshortfile, Lfuncname or Lpackage is in Flags
+	Line       int       // caller line number; set like File
+	Function   string    // caller function qualified by package path; set like File
+	Level      LogLevel  // NoLevel unless written by a leveled method
+	Message    string    // the message, without a trailing newline
+	Fields     []Field   // key/value pairs added with With
+	Stack      []byte    // goroutine stacks, if captured; see SetStackTrace
+}
+
+// hasTime reports whether the time is to be written for r.
+func (r *Record) hasTime() bool {
+	return r.TimeLayout != "" || r.Flags&(Ldate|Ltime|Lmicroseconds|Lnanoseconds) != 0
+}
+
+// appendTime appends the time of r for the JSON and logfmt formatters: in
+// the Logger's time layout if set and in RFC 3339 otherwise.
+func (r *Record) appendTime(b []byte) []byte {
+	if r.TimeLayout == "" {
+		return appendRFC3339(b, r.Time, r.Flags)
+	}
+	t := r.Time
+	if r.Flags&LUTC != 0 {
+		t = t.UTC()
+	}
+	return appendTimeLayout(b, t, r.TimeLayout)
+}
+
+// A Field is a key/value pair attached to a Logger with With.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// TextFormatter is the default Formatter. It writes the prefix and the
+// header selected by the flags, the level name if any, the message and the
+// fields as key=value pairs, e.g.
+//
+//	2009/01/23 01:23:23 d.go:23: INFO hello req=42
+//
+// A stack trace follows on the next lines.
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(buf *[]byte, r *Record) {
+	formatHeader(buf, r)
+	if r.Level != NoLevel {
+		*buf = append(*buf, r.Level.String()...)
+		*buf = append(*buf, ' ')
+	}
+	*buf = append(*buf, r.Message...)
+	formatFields(buf, r.Fields)
+	if stack := r.Stack; len(stack) > 0 {
+		if stack[len(stack)-1] == '\n' {
+			stack = stack[:len(stack)-1]
+		}
+		*buf = append(*buf, '\n')
+		*buf = append(*buf, stack...)
+	}
+}
+
+// formatFields appends the key/value pairs after the message, each
+// preceded by a space. Values that are empty or contain spaces, quotes,
+// '=' or control characters are quoted.
+func formatFields(buf *[]byte, fields []Field) {
+	for _, f := range fields {
+		*buf = append(*buf, ' ')
+		*buf = appendValue(*buf, f.Key)
+		*buf = append(*buf, '=')
+		*buf = appendValue(*buf, fmt.Sprint(f.Value))
+	}
+}
+
+func appendValue(buf []byte, s string) []byte {
+	if !needsQuote(s) {
+		return append(buf, s...)
+	}
+	return strconv.AppendQuote(buf, s)
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
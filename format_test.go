@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+type upperFormatter struct{}
+
+func (upperFormatter) Format(buf *[]byte, r *Record) {
+	*buf = append(*buf, r.Level.String()...)
+	*buf = append(*buf, '|')
+	*buf = append(*buf, r.Message...)
+	for _, f := range r.Fields {
+		*buf = append(*buf, '|')
+		*buf = append(*buf, f.Key...)
+		*buf = append(*buf, '=')
+		*buf = strconv.AppendQuote(*buf, f.Value.(string))
+	}
+	*buf = append(*buf, '|')
+	*buf = append(*buf, r.Prefix...)
+}
+
+func TestSetFormatter(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "P", 0)
+	l.SetFormatter(upperFormatter{})
+	c := l.With("k", "v")
+	c.Errorf("boom\n")
+	l.SetFormatter(nil)
+	l.Println("back")
+	want := "ERROR|boom|k=\"v\"|P\nPback\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestTextFormatterCompatible(t *testing.T) {
+	for _, s := range []string{"", "x", "x\n", "x\n\n", "a\nb"} {
+		var b bytes.Buffer
+		l := New(&b, "P:", 0)
+		l.Output(1, s)
+		want := "P:" + s
+		if len(s) == 0 || s[len(s)-1] != '\n' {
+			want += "\n"
+		}
+		if got := b.String(); got != want {
+			t.Errorf("Output(%q): got %q; want %q", s, got, want)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package log
 // g-log is just helper utility for log interface to link
 // between custom logger and stdlib log package.
 import (
+	"fmt"
 	"io"
 	"log"
+	"sync/atomic"
 
 	"c3/logger"
 )
@@ -15,6 +17,9 @@ const (
 )
 
 // not thread safe - safe it once only
+//
+// Deprecated: nothing consults Level. Use the leveled methods together with
+// SetLevel on the logger instead.
 var Level = LvlNoLog
 
 type Prefixer interface {
@@ -57,6 +62,26 @@ type PrintLogger interface {
 	Printm(m logger.LogMessage)
 }
 
+type LevelLogger interface {
+	Debug(v ...interface{})
+	Debugf(format string, v ...interface{})
+	Info(v ...interface{})
+	Infof(format string, v ...interface{})
+	Warn(v ...interface{})
+	Warnf(format string, v ...interface{})
+	Error(v ...interface{})
+	Errorf(format string, v ...interface{})
+}
+
+type Leveler interface {
+	Level() LogLevel
+	SetLevel(lvl LogLevel)
+}
+
+type StackPrinter interface {
+	PrintStack(skip int)
+}
+
 type PLogger interface {
 	PanicLogger
 	PrintLogger
@@ -71,6 +96,9 @@ type IFLogger interface {
 	FatalLogger
 	PanicLogger
 	PrintLogger
+	LevelLogger
+	Leveler
+	StackPrinter
 }
 
 // StdLog is singleton object for this log package. It is equivalent to
@@ -121,9 +149,35 @@ func (nolog) SetFlags(int) {}
 
 func (nolog) Flags() int { return 0 }
 
+func (nolog) Debug(v ...interface{}) {}
+
+func (nolog) Debugf(format string, v ...interface{}) {}
+
+func (nolog) Info(v ...interface{}) {}
+
+func (nolog) Infof(format string, v ...interface{}) {}
+
+func (nolog) Warn(v ...interface{}) {}
+
+func (nolog) Warnf(format string, v ...interface{}) {}
+
+func (nolog) Error(v ...interface{}) {}
+
+func (nolog) Errorf(format string, v ...interface{}) {}
+
+func (nolog) Level() LogLevel { return DebugLevel }
+
+func (nolog) SetLevel(lvl LogLevel) {}
+
+func (nolog) PrintStack(skip int) {}
+
 // access to stdlib log
 type stdLibLog struct{}
 
+// stdLibLevel is the level shared by every stdLibLog, mirroring the single
+// global logger they write to.
+var stdLibLevel int32
+
 // StdLib allows access to global singleton standard library log object.
 func StdLib() IFLogger {
 	return &stdLibLog{}
@@ -192,3 +246,71 @@ func (stdLibLog) SetFlags(f int) {
 func (stdLibLog) Flags() int {
 	return log.Flags()
 }
+
+func (stdLibLog) leveled(lvl LogLevel, s string) {
+	log.Output(3, lvl.String()+" "+s)
+}
+
+func (stdLibLog) enabled(lvl LogLevel) bool {
+	return lvl >= LogLevel(atomic.LoadInt32(&stdLibLevel))
+}
+
+func (sl stdLibLog) Debug(v ...interface{}) {
+	if sl.enabled(DebugLevel) {
+		sl.leveled(DebugLevel, fmt.Sprint(v...))
+	}
+}
+
+func (sl stdLibLog) Debugf(format string, v ...interface{}) {
+	if sl.enabled(DebugLevel) {
+		sl.leveled(DebugLevel, fmt.Sprintf(format, v...))
+	}
+}
+
+func (sl stdLibLog) Info(v ...interface{}) {
+	if sl.enabled(InfoLevel) {
+		sl.leveled(InfoLevel, fmt.Sprint(v...))
+	}
+}
+
+func (sl stdLibLog) Infof(format string, v ...interface{}) {
+	if sl.enabled(InfoLevel) {
+		sl.leveled(InfoLevel, fmt.Sprintf(format, v...))
+	}
+}
+
+func (sl stdLibLog) Warn(v ...interface{}) {
+	if sl.enabled(WarnLevel) {
+		sl.leveled(WarnLevel, fmt.Sprint(v...))
+	}
+}
+
+func (sl stdLibLog) Warnf(format string, v ...interface{}) {
+	if sl.enabled(WarnLevel) {
+		sl.leveled(WarnLevel, fmt.Sprintf(format, v...))
+	}
+}
+
+func (sl stdLibLog) Error(v ...interface{}) {
+	if sl.enabled(ErrorLevel) {
+		sl.leveled(ErrorLevel, fmt.Sprint(v...))
+	}
+}
+
+func (sl stdLibLog) Errorf(format string, v ...interface{}) {
+	if sl.enabled(ErrorLevel) {
+		sl.leveled(ErrorLevel, fmt.Sprintf(format, v...))
+	}
+}
+
+func (stdLibLog) Level() LogLevel {
+	return LogLevel(atomic.LoadInt32(&stdLibLevel))
+}
+
+func (stdLibLog) SetLevel(lvl LogLevel) {
+	atomic.StoreInt32(&stdLibLevel, int32(lvl))
+}
+
+func (stdLibLog) PrintStack(skip int) {
+	log.Output(2+skip, "stack trace\n"+string(captureStack(skip+1, StackCurrent)))
+}
@@ -0,0 +1,208 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// JSONFormatter writes each entry as a single-line JSON object, e.g.
+//
+//	{"time":"2009-01-23T01:23:23Z","level":"INFO","prefix":"app: ","caller":"d.go:23","msg":"hello","req":42}
+//
+// The keys appear in that order, followed by the fields added with With
+// and by "stack" if a stack trace was captured.
+// "time" is written when a time layout is set or any of Ldate, Ltime,
+// Lmicroseconds or Lnanoseconds is set: as a number for the Unix* layouts,
+// in the time layout if set and otherwise in RFC 3339 with the resolution
+// selected by Lmicroseconds or Lnanoseconds. It is in UTC if LUTC is set.
+// "caller" is written when Llongfile or Lshortfile is set and is followed
+// by "func" when Lfuncname or Lpackage is set; "level" and "prefix" are
+// omitted when empty. Logging through JSONFormatter does not
+// allocate unless a field holds a value of a non-scalar type.
+type JSONFormatter struct{}
+
+// Format implements Formatter.
+func (JSONFormatter) Format(buf *[]byte, r *Record) {
+	b := append(*buf, '{')
+	if r.hasTime() {
+		b = append(b, `"time":`...)
+		if isUnixLayout(r.TimeLayout) {
+			b = r.appendTime(b)
+		} else {
+			b = append(b, '"')
+			start := len(b)
+			b = r.appendTime(b)
+			if needsJSONEscape(b[start:]) {
+				// Only custom layouts with odd literals get here.
+				b = appendJSONString(b[:start-1], string(b[start:]))
+			} else {
+				b = append(b, '"')
+			}
+		}
+		b = append(b, ',')
+	}
+	if r.Level != NoLevel {
+		b = append(b, `"level":"`...)
+		b = append(b, r.Level.String()...)
+		b = append(b, `",`...)
+	}
+	if r.Prefix != "" {
+		b = append(b, `"prefix":`...)
+		b = appendJSONString(b, r.Prefix)
+		b = append(b, ',')
+	}
+	if r.Flags&(Lshortfile|Llongfile) != 0 {
+		file := r.File
+		if r.Flags&Lshortfile != 0 {
+			file = shortFile(file)
+		}
+		b = append(b, `"caller":`...)
+		b = appendJSONString(b, file)
+		b = b[:len(b)-1] // reopen the string to add the line.
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(r.Line), 10)
+		b = append(b, `",`...)
+	}
+	if name := funcName(r.Function, r.Flags); name != "" {
+		b = append(b, `"func":`...)
+		b = appendJSONString(b, name)
+		b = append(b, ',')
+	}
+	b = append(b, `"msg":`...)
+	b = appendJSONString(b, r.Message)
+	for _, f := range r.Fields {
+		b = append(b, ',')
+		b = appendJSONString(b, f.Key)
+		b = append(b, ':')
+		b = appendJSONValue(b, f.Value)
+	}
+	if len(r.Stack) > 0 {
+		b = append(b, `,"stack":`...)
+		b = appendJSONString(b, string(r.Stack))
+	}
+	*buf = append(b, '}')
+}
+
+// appendJSONValue appends v encoded as JSON. Common scalar types are
+// encoded in place; errors and fmt.Stringers become strings; anything else
+// goes through encoding/json, falling back to its fmt.Sprint form.
+func appendJSONValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJSONString(b, v)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float32:
+		return appendJSONFloat(b, float64(v), 32)
+	case float64:
+		return appendJSONFloat(b, v, 64)
+	case error:
+		return appendJSONString(b, v.Error())
+	case fmt.Stringer:
+		return appendJSONString(b, v.String())
+	}
+	enc, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(b, fmt.Sprint(v))
+	}
+	return append(b, enc...)
+}
+
+// appendJSONFloat appends f as a JSON number, or as a string for NaN and
+// the infinities, which JSON cannot represent.
+func appendJSONFloat(b []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		b = append(b, '"')
+		b = strconv.AppendFloat(b, f, 'g', -1, bits)
+		return append(b, '"')
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, bits)
+}
+
+const hex = "0123456789abcdef"
+
+// needsJSONEscape reports whether appendJSONString might escape or replace
+// any byte of b. Non-ASCII bytes are reported conservatively.
+func needsJSONEscape(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// appendJSONString appends s as a quoted JSON string. Control characters,
+// U+2028 and U+2029 are escaped and invalid UTF-8 is replaced by U+FFFD.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "app: ", LJSON|Lshortfile).With("req", 42, "err", errors.New("boom"), "ok", true)
+	l.Warnf("quote \" backslash \\ nl \n tab \t ctl \x01 bad \xff sep \u2028")
+	line := b.String()
+	pattern := `^\{"level":"WARN","prefix":"app: ","caller":"json_test\.go:[0-9]+","msg":"quote \\" backslash \\\\ nl \\n tab \\t ctl \\u0001 bad \\ufffd sep \\u2028","req":42,"err":"boom","ok":true\}\n$`
+	if matched, _ := regexp.MatchString(pattern, line); !matched {
+		t.Errorf("log output should match %q is %q", pattern, line)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Errorf("output is not valid JSON: %v", err)
+	}
+}
+
+func TestJSONTime(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", LJSON|LstdFlags|Lmicroseconds|LUTC)
+	l.Println("hello")
+	pattern := `^\{"time":"` + `[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}\.[0-9]{6}Z` + `","msg":"hello"\}\n$`
+	if matched, _ := regexp.MatchString(pattern, b.String()); !matched {
+		t.Errorf("log output should match %q is %q", pattern, b.String())
+	}
+}
+
+func TestJSONAllocs(t *testing.T) {
+	l := New(ioutil.Discard, "", LJSON|LstdFlags|Lmicroseconds)
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Output(1, "hello")
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs per line; want 0", allocs)
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	const testString = "test"
+	var buf bytes.Buffer
+	l := New(&buf, "", LJSON|LstdFlags)
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		l.Println(testString)
+	}
+}
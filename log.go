@@ -16,8 +16,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"c3/logger"
@@ -26,8 +27,9 @@ import (
 // These flags define which text to prefix to each log entry generated by the Logger.
 const (
 	// Bits or'ed together to control what's printed.
-	// There is no control over the order they appear (the order listed
-	// here) or the format they present (as described in the comments).
+	// With the default TextFormatter there is no control over the order
+	// they appear (the order listed here) or the format they present (as
+	// described in the comments); use SetFormatter for other layouts.
 	// The prefix is followed by a colon only when Llongfile or Lshortfile
 	// is specified.
 	// For example, flags Ldate | Ltime (or LstdFlags) produce,
@@ -40,19 +42,78 @@ const (
 	Llongfile                     // full file name and line number: /a/b/c/d.go:23
 	Lshortfile                    // final file name element and line number: d.go:23. overrides Llongfile
 	LUTC                          // if Ldate or Ltime is set, use UTC rather than the local time zone
+	LJSON                         // without a Formatter, write entries with JSONFormatter
+	Llogfmt                       // without a Formatter, write entries with LogfmtFormatter. LJSON takes precedence
+	Lnanoseconds                  // nanosecond resolution: 01:23:23.123123123.  assumes Ltime, overrides Lmicroseconds.
+	Ltimezone                     // zone offset after the date and time: 01:23:23 +01:00
+	Lfuncname                     // caller function name: (*T).run.func1
+	Lpackage                      // caller package path: a/b/c; with Lfuncname: a/b/c.(*T).run.func1
 	LstdFlags     = Ldate | Ltime // initial values for the standard logger
 )
 
+// A LogLevel is the severity of a message written by the leveled methods
+// (Debug, Info, Warn and Error). Messages below the Logger's level are
+// discarded.
+type LogLevel int32
+
+// Severities in increasing order of importance.
+const (
+	DebugLevel LogLevel = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+
+	// NoLevel marks messages written through Print, Fatal, Panic and Output.
+	// They are never filtered and carry no severity in the output.
+	NoLevel LogLevel = -1
+)
+
+var levelNames = [...]string{
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO",
+	WarnLevel:  "WARN",
+	ErrorLevel: "ERROR",
+}
+
+// String returns the upper-case name of the level, e.g. "INFO".
+func (lvl LogLevel) String() string {
+	if lvl >= 0 && int(lvl) < len(levelNames) {
+		return levelNames[lvl]
+	}
+	if lvl == NoLevel {
+		return ""
+	}
+	return fmt.Sprintf("LEVEL(%d)", int32(lvl))
+}
+
+// A sink is the destination shared by a Logger and every Logger derived
+// from it with With.
+type sink struct {
+	mu    sync.Mutex   // ensures atomic writes; protects out, buf, rec, async and the Logger fields
+	out   io.Writer    // destination for output
+	buf   []byte       // for accumulating text to write
+	rec   Record       // entry being formatted
+	async *asyncWriter // queue of entries when writing asynchronously; see SetAsync
+}
+
 // A Logger represents an active logging object that generates lines of
 // output to an io.Writer.  Each logging operation makes a single call to
 // the Writer's Write method.  A Logger can be used simultaneously from
 // multiple goroutines; it guarantees to serialize access to the Writer.
 type Logger struct {
-	mu     sync.Mutex // ensures atomic writes; protects the following fields
-	prefix string     // prefix to write at beginning of each line
-	flag   int        // properties
-	out    io.Writer  // destination for output
-	buf    []byte     // for accumulating text to write
+	*sink
+	prefix    string    // prefix to write at beginning of each line
+	flag      int       // properties
+	level     int32     // minimum LogLevel written; accessed atomically
+	fields    []Field   // key/value pairs written after each message; never modified
+	formatter Formatter // lays out each entry; nil selects one from flag
+	layout    string    // time layout set with SetTimeLayout
+	stack     int32     // StackTrace of Fatal and Panic entries; accessed atomically
+
+	exitFunc  func(code int)                             // called by Fatal; nil means os.Exit
+	exitCode  int                                        // passed to exitFunc
+	exitHooks []func()                                   // run by Fatal before exitFunc
+	panicFunc func(s, file string, line int) interface{} // makes the value for panic; nil means s
 }
 
 // New creates a new Logger.   The out variable sets the
@@ -60,7 +121,41 @@ type Logger struct {
 // The prefix appears at the beginning of each generated log line.
 // The flag argument defines the logging properties.
 func New(out io.Writer, prefix string, flag int) *Logger {
-	return &Logger{out: out, prefix: prefix, flag: flag}
+	return &Logger{sink: &sink{out: out}, prefix: prefix, flag: flag, exitCode: 1}
+}
+
+// With returns a Logger that writes kv as key/value pairs after the message
+// of every line, following any pairs already carried by l. Keys are
+// formatted with fmt.Sprint; a trailing key without a value is paired with
+// "MISSING". The new Logger starts with l's prefix, flags, level, time
+// layout, stack trace, exit and panic settings and formatter, and shares
+// its output and mutex, so SetOutput on either affects both.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fields := make([]Field, len(l.fields), len(l.fields)+(len(kv)+1)/2)
+	copy(fields, l.fields)
+	for i := 0; i < len(kv); i += 2 {
+		f := Field{Key: fmt.Sprint(kv[i]), Value: "MISSING"}
+		if i+1 < len(kv) {
+			f.Value = kv[i+1]
+		}
+		fields = append(fields, f)
+	}
+	return &Logger{
+		sink:      l.sink,
+		prefix:    l.prefix,
+		flag:      l.flag,
+		level:     atomic.LoadInt32(&l.level),
+		fields:    fields,
+		formatter: l.formatter,
+		layout:    l.layout,
+		stack:     atomic.LoadInt32(&l.stack),
+		exitFunc:  l.exitFunc,
+		exitCode:  l.exitCode,
+		exitHooks: l.exitHooks[:len(l.exitHooks):len(l.exitHooks)],
+		panicFunc: l.panicFunc,
+	}
 }
 
 // SetOutput sets the output destination for the logger.
@@ -89,13 +184,85 @@ func itoa(buf *[]byte, i int, wid int) {
 	*buf = append(*buf, b[bp:]...)
 }
 
-func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
-	*buf = append(*buf, l.prefix...)
-	if l.flag&LUTC != 0 {
+// Layouts accepted by SetTimeLayout in addition to those of time.Format.
+// They write the time as an integer count since the Unix epoch.
+const (
+	UnixSeconds = "unix"
+	UnixMillis  = "unixmilli"
+	UnixMicros  = "unixmicro"
+	UnixNanos   = "unixnano"
+)
+
+// Timestamp layouts used by JSONFormatter and LogfmtFormatter.
+const (
+	rfc3339      = "2006-01-02T15:04:05Z07:00"
+	rfc3339Micro = "2006-01-02T15:04:05.000000Z07:00"
+	rfc3339Nano  = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// isUnixLayout reports whether layout is one of the Unix* layouts.
+func isUnixLayout(layout string) bool {
+	switch layout {
+	case UnixSeconds, UnixMillis, UnixMicros, UnixNanos:
+		return true
+	}
+	return false
+}
+
+// appendTimeLayout appends t formatted with layout, which is one of the
+// Unix* layouts or a layout for time.Format.
+func appendTimeLayout(b []byte, t time.Time, layout string) []byte {
+	switch layout {
+	case UnixSeconds:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	case UnixMillis:
+		return strconv.AppendInt(b, t.UnixNano()/1e6, 10)
+	case UnixMicros:
+		return strconv.AppendInt(b, t.UnixNano()/1e3, 10)
+	case UnixNanos:
+		return strconv.AppendInt(b, t.UnixNano(), 10)
+	}
+	return t.AppendFormat(b, layout)
+}
+
+// appendRFC3339 appends t in RFC 3339, with nanoseconds or microseconds if
+// Lnanoseconds or Lmicroseconds is set in flag and in UTC if LUTC is set.
+func appendRFC3339(b []byte, t time.Time, flag int) []byte {
+	if flag&LUTC != 0 {
+		t = t.UTC()
+	}
+	switch {
+	case flag&Lnanoseconds != 0:
+		return t.AppendFormat(b, rfc3339Nano)
+	case flag&Lmicroseconds != 0:
+		return t.AppendFormat(b, rfc3339Micro)
+	}
+	return t.AppendFormat(b, rfc3339)
+}
+
+// shortFile returns the final element of a file name reported by runtime.Caller.
+func shortFile(file string) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			return file[i+1:]
+		}
+	}
+	return file
+}
+
+// formatHeader appends the prefix and the date, time and caller selected by
+// the flags of r in the classic layout.
+func formatHeader(buf *[]byte, r *Record) {
+	*buf = append(*buf, r.Prefix...)
+	t, file := r.Time, r.File
+	if r.Flags&LUTC != 0 {
 		t = t.UTC()
 	}
-	if l.flag&(Ldate|Ltime|Lmicroseconds) != 0 {
-		if l.flag&Ldate != 0 {
+	if r.TimeLayout != "" {
+		*buf = appendTimeLayout(*buf, t, r.TimeLayout)
+		*buf = append(*buf, ' ')
+	} else if r.Flags&(Ldate|Ltime|Lmicroseconds|Lnanoseconds) != 0 {
+		if r.Flags&Ldate != 0 {
 			year, month, day := t.Date()
 			itoa(buf, year, 4)
 			*buf = append(*buf, '/')
@@ -104,34 +271,47 @@ func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
 			itoa(buf, day, 2)
 			*buf = append(*buf, ' ')
 		}
-		if l.flag&(Ltime|Lmicroseconds) != 0 {
+		if r.Flags&(Ltime|Lmicroseconds|Lnanoseconds) != 0 {
 			hour, min, sec := t.Clock()
 			itoa(buf, hour, 2)
 			*buf = append(*buf, ':')
 			itoa(buf, min, 2)
 			*buf = append(*buf, ':')
 			itoa(buf, sec, 2)
-			if l.flag&Lmicroseconds != 0 {
+			if r.Flags&Lnanoseconds != 0 {
+				*buf = append(*buf, '.')
+				itoa(buf, t.Nanosecond(), 9)
+			} else if r.Flags&Lmicroseconds != 0 {
 				*buf = append(*buf, '.')
 				itoa(buf, t.Nanosecond()/1e3, 6)
 			}
 			*buf = append(*buf, ' ')
 		}
-	}
-	if l.flag&(Lshortfile|Llongfile) != 0 {
-		if l.flag&Lshortfile != 0 {
-			short := file
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					short = file[i+1:]
-					break
-				}
+		if r.Flags&Ltimezone != 0 {
+			_, offset := t.Zone()
+			sign := byte('+')
+			if offset < 0 {
+				sign = '-'
+				offset = -offset
 			}
-			file = short
+			*buf = append(*buf, sign)
+			itoa(buf, offset/3600, 2)
+			*buf = append(*buf, ':')
+			itoa(buf, offset%3600/60, 2)
+			*buf = append(*buf, ' ')
+		}
+	}
+	if r.Flags&(Lshortfile|Llongfile) != 0 {
+		if r.Flags&Lshortfile != 0 {
+			file = shortFile(file)
 		}
 		*buf = append(*buf, file...)
 		*buf = append(*buf, ':')
-		itoa(buf, line, -1)
+		itoa(buf, r.Line, -1)
+		*buf = append(*buf, ": "...)
+	}
+	if name := funcName(r.Function, r.Flags); name != "" {
+		*buf = append(*buf, name...)
 		*buf = append(*buf, ": "...)
 	}
 }
@@ -143,32 +323,70 @@ func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
 // provided for generality, although at the moment on all pre-defined
 // paths it will be 2.
 func (l *Logger) Output(calldepth int, s string) error {
+	return l.output(calldepth+1, NoLevel, s, StackNone) // +1 for this frame.
+}
+
+// output is Output with a level and a stack trace of the kind st, which
+// is captured before taking the lock.
+func (l *Logger) output(calldepth int, lvl LogLevel, s string, st StackTrace) error {
 	now := time.Now() // get this early.
+	var stack []byte
+	if st != StackNone {
+		stack = captureStack(calldepth, st)
+	}
 	var file string
 	var line int
+	var fn string
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	if l.flag&(Lshortfile|Llongfile) != 0 {
+	if l.flag&(Lshortfile|Llongfile|Lfuncname|Lpackage) != 0 {
 		// release lock while getting caller info - it's expensive.
 		l.mu.Unlock()
-		var ok bool
-		_, file, line, ok = runtime.Caller(calldepth)
-		if !ok {
-			file = "???"
-			line = 0
-		}
+		file, line, fn = caller(calldepth)
 		l.mu.Lock()
 	}
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1] // fields go before the newline.
+	}
+	l.rec = Record{
+		Time:       now,
+		TimeLayout: l.layout,
+		Flags:      l.flag,
+		Prefix:     l.prefix,
+		File:       file,
+		Line:       line,
+		Function:   fn,
+		Level:      lvl,
+		Message:    s,
+		Fields:     l.fields,
+		Stack:      stack,
+	}
 	l.buf = l.buf[:0]
-	l.formatHeader(&l.buf, now, file, line)
-	l.buf = append(l.buf, s...)
-	if len(s) == 0 || s[len(s)-1] != '\n' {
-		l.buf = append(l.buf, '\n')
+	l.formatterLocked().Format(&l.buf, &l.rec)
+	l.rec = Record{} // don't pin the message until the next entry.
+	l.buf = append(l.buf, '\n')
+	if l.async != nil {
+		l.async.enqueue(l.out, l.buf, l)
+		return nil
 	}
 	_, err := l.out.Write(l.buf)
 	return err
 }
 
+// formatterLocked returns the Formatter set with SetFormatter or, failing
+// that, the one selected by the flags. l.mu must be held.
+func (l *Logger) formatterLocked() Formatter {
+	switch {
+	case l.formatter != nil:
+		return l.formatter
+	case l.flag&LJSON != 0:
+		return JSONFormatter{}
+	case l.flag&Llogfmt != 0:
+		return LogfmtFormatter{}
+	}
+	return TextFormatter{}
+}
+
 // Printf calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Printf(format string, v ...interface{}) {
@@ -185,43 +403,114 @@ func (l *Logger) Println(v ...interface{}) { l.Output(2, fmt.Sprintln(v...)) }
 
 func (l *Logger) Printm(m logger.LogMessage) { l.Output(2, m.String()) }
 
-// Fatal is equivalent to l.Print() followed by a call to os.Exit(1).
+// Fatal is equivalent to l.Print() followed by a call to os.Exit(1); see SetExitFunc.
 func (l *Logger) Fatal(v ...interface{}) {
-	l.Output(2, fmt.Sprint(v...))
-	os.Exit(1)
+	l.output(2, NoLevel, fmt.Sprint(v...), l.StackTrace())
+	l.exit()
 }
 
-// Fatalf is equivalent to l.Printf() followed by a call to os.Exit(1).
+// Fatalf is equivalent to l.Printf() followed by a call to os.Exit(1); see SetExitFunc.
 func (l *Logger) Fatalf(format string, v ...interface{}) {
-	l.Output(2, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	l.output(2, NoLevel, fmt.Sprintf(format, v...), l.StackTrace())
+	l.exit()
 }
 
-// Fatalln is equivalent to l.Println() followed by a call to os.Exit(1).
+// Fatalln is equivalent to l.Println() followed by a call to os.Exit(1); see SetExitFunc.
 func (l *Logger) Fatalln(v ...interface{}) {
-	l.Output(2, fmt.Sprintln(v...))
-	os.Exit(1)
+	l.output(2, NoLevel, fmt.Sprintln(v...), l.StackTrace())
+	l.exit()
 }
 
-// Panic is equivalent to l.Print() followed by a call to panic().
+// Panic is equivalent to l.Print() followed by a call to panic(); see SetPanicFunc.
 func (l *Logger) Panic(v ...interface{}) {
 	s := fmt.Sprint(v...)
-	l.Output(2, s)
-	panic(s)
+	l.output(2, NoLevel, s, l.StackTrace())
+	panic(l.panicValue(2, s))
 }
 
-// Panicf is equivalent to l.Printf() followed by a call to panic().
+// Panicf is equivalent to l.Printf() followed by a call to panic(); see SetPanicFunc.
 func (l *Logger) Panicf(format string, v ...interface{}) {
 	s := fmt.Sprintf(format, v...)
-	l.Output(2, s)
-	panic(s)
+	l.output(2, NoLevel, s, l.StackTrace())
+	panic(l.panicValue(2, s))
 }
 
-// Panicln is equivalent to l.Println() followed by a call to panic().
+// Panicln is equivalent to l.Println() followed by a call to panic(); see SetPanicFunc.
 func (l *Logger) Panicln(v ...interface{}) {
 	s := fmt.Sprintln(v...)
-	l.Output(2, s)
-	panic(s)
+	l.output(2, NoLevel, s, l.StackTrace())
+	panic(l.panicValue(2, s))
+}
+
+// Debug logs at DebugLevel. Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Debug(v ...interface{}) {
+	if l.enabled(DebugLevel) {
+		l.output(2, DebugLevel, fmt.Sprint(v...), StackNone)
+	}
+}
+
+// Debugf logs at DebugLevel. Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	if l.enabled(DebugLevel) {
+		l.output(2, DebugLevel, fmt.Sprintf(format, v...), StackNone)
+	}
+}
+
+// Info logs at InfoLevel. Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Info(v ...interface{}) {
+	if l.enabled(InfoLevel) {
+		l.output(2, InfoLevel, fmt.Sprint(v...), StackNone)
+	}
+}
+
+// Infof logs at InfoLevel. Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Infof(format string, v ...interface{}) {
+	if l.enabled(InfoLevel) {
+		l.output(2, InfoLevel, fmt.Sprintf(format, v...), StackNone)
+	}
+}
+
+// Warn logs at WarnLevel. Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Warn(v ...interface{}) {
+	if l.enabled(WarnLevel) {
+		l.output(2, WarnLevel, fmt.Sprint(v...), StackNone)
+	}
+}
+
+// Warnf logs at WarnLevel. Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	if l.enabled(WarnLevel) {
+		l.output(2, WarnLevel, fmt.Sprintf(format, v...), StackNone)
+	}
+}
+
+// Error logs at ErrorLevel. Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Error(v ...interface{}) {
+	if l.enabled(ErrorLevel) {
+		l.output(2, ErrorLevel, fmt.Sprint(v...), StackNone)
+	}
+}
+
+// Errorf logs at ErrorLevel. Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	if l.enabled(ErrorLevel) {
+		l.output(2, ErrorLevel, fmt.Sprintf(format, v...), StackNone)
+	}
+}
+
+func (l *Logger) enabled(lvl LogLevel) bool {
+	return lvl >= LogLevel(atomic.LoadInt32(&l.level))
+}
+
+// Level returns the minimum level written by the logger.
+func (l *Logger) Level() LogLevel {
+	return LogLevel(atomic.LoadInt32(&l.level))
+}
+
+// SetLevel sets the minimum level written by the logger. It is safe to call
+// while other goroutines are logging.
+func (l *Logger) SetLevel(lvl LogLevel) {
+	atomic.StoreInt32(&l.level, int32(lvl))
 }
 
 // Flags returns the output flags for the logger.
@@ -238,6 +527,34 @@ func (l *Logger) SetFlags(flag int) {
 	l.flag = flag
 }
 
+// SetFormatter sets the Formatter that lays out each entry of the logger.
+// A nil Formatter restores the default: JSONFormatter if LJSON is set,
+// LogfmtFormatter if Llogfmt is set and TextFormatter otherwise.
+func (l *Logger) SetFormatter(f Formatter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.formatter = f
+}
+
+// TimeLayout returns the time layout of the logger.
+func (l *Logger) TimeLayout() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.layout
+}
+
+// SetTimeLayout sets the layout of the time written at the start of each
+// entry. It is one of UnixSeconds, UnixMillis, UnixMicros or UnixNanos, or
+// a layout for time.Format such as time.RFC3339Nano. When a layout is set,
+// the time is always written and replaces the date and time selected by
+// Ldate, Ltime, Lmicroseconds, Lnanoseconds and Ltimezone; LUTC still
+// applies. An empty layout restores the flag-driven formats.
+func (l *Logger) SetTimeLayout(layout string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.layout = layout
+}
+
 // Prefix returns the output prefix for the logger.
 func (l *Logger) Prefix() string {
 	l.mu.Lock()
@@ -299,43 +616,43 @@ func Println(v ...interface{}) {
 	std.Output(2, fmt.Sprintln(v...))
 }
 
-// Fatal is equivalent to Print() followed by a call to os.Exit(1).
+// Fatal is equivalent to Print() followed by a call to os.Exit(1); see SetExitFunc.
 func Fatal(v ...interface{}) {
-	std.Output(2, fmt.Sprint(v...))
-	os.Exit(1)
+	std.output(2, NoLevel, fmt.Sprint(v...), std.StackTrace())
+	std.exit()
 }
 
-// Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
+// Fatalf is equivalent to Printf() followed by a call to os.Exit(1); see SetExitFunc.
 func Fatalf(format string, v ...interface{}) {
-	std.Output(2, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	std.output(2, NoLevel, fmt.Sprintf(format, v...), std.StackTrace())
+	std.exit()
 }
 
-// Fatalln is equivalent to Println() followed by a call to os.Exit(1).
+// Fatalln is equivalent to Println() followed by a call to os.Exit(1); see SetExitFunc.
 func Fatalln(v ...interface{}) {
-	std.Output(2, fmt.Sprintln(v...))
-	os.Exit(1)
+	std.output(2, NoLevel, fmt.Sprintln(v...), std.StackTrace())
+	std.exit()
 }
 
-// Panic is equivalent to Print() followed by a call to panic().
+// Panic is equivalent to Print() followed by a call to panic(); see SetPanicFunc.
 func Panic(v ...interface{}) {
 	s := fmt.Sprint(v...)
-	std.Output(2, s)
-	panic(s)
+	std.output(2, NoLevel, s, std.StackTrace())
+	panic(std.panicValue(2, s))
 }
 
-// Panicf is equivalent to Printf() followed by a call to panic().
+// Panicf is equivalent to Printf() followed by a call to panic(); see SetPanicFunc.
 func Panicf(format string, v ...interface{}) {
 	s := fmt.Sprintf(format, v...)
-	std.Output(2, s)
-	panic(s)
+	std.output(2, NoLevel, s, std.StackTrace())
+	panic(std.panicValue(2, s))
 }
 
-// Panicln is equivalent to Println() followed by a call to panic().
+// Panicln is equivalent to Println() followed by a call to panic(); see SetPanicFunc.
 func Panicln(v ...interface{}) {
 	s := fmt.Sprintln(v...)
-	std.Output(2, s)
-	panic(s)
+	std.output(2, NoLevel, s, std.StackTrace())
+	panic(std.panicValue(2, s))
 }
 
 // Output writes the output for a logging event.  The string s contains
@@ -145,6 +145,43 @@ func TestUTCFlag(t *testing.T) {
 	t.Errorf("got %q; want %q", got, want)
 }
 
+func TestTimeFlags(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", Ltime|Lnanoseconds|Ltimezone|LUTC)
+	l.Print("hello")
+	pattern := "^" + Rtime + `\.[0-9]{9} \+00:00 hello\n$`
+	if matched, _ := regexp.MatchString(pattern, b.String()); !matched {
+		t.Errorf("log output should match %q is %q", pattern, b.String())
+	}
+}
+
+func TestSetTimeLayout(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "P:", 0)
+	l.SetTimeLayout(time.RFC3339Nano)
+	if got := l.TimeLayout(); got != time.RFC3339Nano {
+		t.Errorf("TimeLayout: got %q; want %q", got, time.RFC3339Nano)
+	}
+	before := time.Now()
+	l.Print("hello")
+	line := strings.TrimSuffix(strings.TrimPrefix(b.String(), "P:"), " hello\n")
+	ts, err := time.Parse(time.RFC3339Nano, line)
+	if err != nil {
+		t.Fatalf("output %q: %v", b.String(), err)
+	}
+	if ts.Before(before) {
+		t.Errorf("time %v is before %v", ts, before)
+	}
+
+	b.Reset()
+	l.SetTimeLayout(UnixMillis)
+	l.SetFlags(LJSON)
+	l.Print("hello")
+	if matched, _ := regexp.MatchString(`^\{"time":[0-9]{13},"prefix":"P:","msg":"hello"\}\n$`, b.String()); !matched {
+		t.Errorf("unexpected JSON output %q", b.String())
+	}
+}
+
 func TestEmptyPrintCreatesLine(t *testing.T) {
 	var b bytes.Buffer
 	l := New(&b, "Header:", LstdFlags)
@@ -159,6 +196,65 @@ func TestEmptyPrintCreatesLine(t *testing.T) {
 	}
 }
 
+func TestLevel(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	if lvl := l.Level(); lvl != DebugLevel {
+		t.Errorf("default level: expected %v got %v", DebugLevel, lvl)
+	}
+	l.SetLevel(WarnLevel)
+	l.Debug("debug")
+	l.Infof("info %d", 1)
+	l.Warn("warn")
+	l.Errorf("error %d", 2)
+	l.Print("plain")
+	want := "WARN warn\nERROR error 2\nplain\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+type stringer struct{ called *bool }
+
+func (s stringer) String() string {
+	*s.called = true
+	return "called"
+}
+
+func TestLevelSkipsFormatting(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	l.SetLevel(ErrorLevel)
+	var called bool
+	l.Infof("%v", stringer{&called})
+	if called || b.Len() != 0 {
+		t.Errorf("filtered message was formatted: %q", b.String())
+	}
+}
+
+func TestWith(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "P:", 0)
+	c := l.With("req", 42, "user", "a b")
+	cc := c.With("tenant")
+	l.Println("parent")
+	c.Println("child")
+	cc.Warnf("grandchild")
+	want := "P:parent\n" +
+		"P:child req=42 user=\"a b\"\n" +
+		"P:WARN grandchild req=42 user=\"a b\" tenant=MISSING\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	var b2 bytes.Buffer
+	l.SetOutput(&b2)
+	c.Print("moved")
+	if got, want := b2.String(), "P:moved req=42 user=\"a b\"\n"; got != want {
+		t.Errorf("after SetOutput on parent: got %q; want %q", got, want)
+	}
+}
+
 func BenchmarkItoa(b *testing.B) {
 	dst := make([]byte, 0, 64)
 	for i := 0; i < b.N; i++ {
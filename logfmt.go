@@ -0,0 +1,66 @@
+package log
+
+import "strconv"
+
+// LogfmtFormatter writes each entry as a line of logfmt key=value pairs, e.g.
+//
+//	ts=2009-01-23T01:23:23Z level=INFO prefix=app: caller=d.go:23 msg="hello world" req=42
+//
+// The keys appear in that order, with func after caller, followed by the
+// fields added with With and by stack if a stack trace was captured. They
+// are written under the same conditions as by JSONFormatter. Values that
+// are empty or contain spaces, quotes, '=' or control characters are quoted
+// in the manner of strconv.Quote.
+type LogfmtFormatter struct{}
+
+// Format implements Formatter.
+func (LogfmtFormatter) Format(buf *[]byte, r *Record) {
+	b := *buf
+	if r.hasTime() {
+		b = append(b, "ts="...)
+		start := len(b)
+		b = r.appendTime(b)
+		if needsQuote(string(b[start:])) {
+			b = strconv.AppendQuote(b[:start], string(b[start:]))
+		}
+		b = append(b, ' ')
+	}
+	if r.Level != NoLevel {
+		b = append(b, "level="...)
+		b = append(b, r.Level.String()...)
+		b = append(b, ' ')
+	}
+	if r.Prefix != "" {
+		b = append(b, "prefix="...)
+		b = appendValue(b, r.Prefix)
+		b = append(b, ' ')
+	}
+	if r.Flags&(Lshortfile|Llongfile) != 0 {
+		file := r.File
+		if r.Flags&Lshortfile != 0 {
+			file = shortFile(file)
+		}
+		b = append(b, "caller="...)
+		if needsQuote(file) {
+			b = strconv.AppendQuote(b, file+":"+strconv.Itoa(r.Line))
+		} else {
+			b = append(b, file...)
+			b = append(b, ':')
+			b = strconv.AppendInt(b, int64(r.Line), 10)
+		}
+		b = append(b, ' ')
+	}
+	if name := funcName(r.Function, r.Flags); name != "" {
+		b = append(b, "func="...)
+		b = appendValue(b, name)
+		b = append(b, ' ')
+	}
+	b = append(b, "msg="...)
+	b = appendValue(b, r.Message)
+	formatFields(&b, r.Fields)
+	if len(r.Stack) > 0 {
+		b = append(b, " stack="...)
+		b = strconv.AppendQuote(b, string(r.Stack))
+	}
+	*buf = b
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestLogfmt(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "app:", Llogfmt|Lshortfile).With("req", 42, "user", `a "b"`, "empty", "")
+	l.Info("hello world\nsecond line")
+	l.Print("plain")
+	pattern := `^level=INFO prefix=app: caller=logfmt_test\.go:[0-9]+ msg="hello world\\nsecond line" req=42 user="a \\"b\\"" empty=""\n` +
+		`prefix=app: caller=logfmt_test\.go:[0-9]+ msg=plain req=42 user="a \\"b\\"" empty=""\n$`
+	if matched, _ := regexp.MatchString(pattern, b.String()); !matched {
+		t.Errorf("log output should match %q is %q", pattern, b.String())
+	}
+}
+
+func TestLogfmtTime(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", Llogfmt|Ldate|LUTC)
+	l.Println("hello")
+	pattern := `^ts=[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}Z msg=hello\n$`
+	if matched, _ := regexp.MatchString(pattern, b.String()); !matched {
+		t.Errorf("log output should match %q is %q", pattern, b.String())
+	}
+}
@@ -0,0 +1,61 @@
+package logrot
+
+import "os"
+
+// WithFdCapture makes CaptureStdout and CaptureStderr redirect the file
+// descriptors 1 and 2 to the log file, again on every rotation, instead of
+// only reassigning os.Stdout and os.Stderr. This also captures the output
+// of cgo code, of child processes that inherit the descriptors and of
+// runtime panics. Close restores the original descriptors. It is only
+// supported on Linux and ignored elsewhere.
+func WithFdCapture() Option {
+	return func(rl *LogRot) {
+		rl.fdCapture = fdCaptureSupported
+	}
+}
+
+// captureFdLocked prepares to capture the descriptor fd, 1 or 2, by saving
+// the original in *saved unless it already was. It reports whether
+// capturing can start.
+// rl.mu must be held.
+func (rl *LogRot) captureFdLocked(fd int, saved **os.File) bool {
+	if *saved != nil {
+		return true
+	}
+	f, err := saveFd(fd)
+	if err != nil {
+		go rl.report(err) // lg may write to rl.
+		return false
+	}
+	*saved = f
+	return true
+}
+
+// dupStdLocked makes the captured descriptors refer to the log file, or to
+// the original stderr while falling back to it. rl.mu must be held.
+func (rl *LogRot) dupStdLocked() {
+	f := rl.logFile
+	if f == rl.stderr && rl.savedStderr != nil {
+		f = rl.savedStderr
+	}
+	for fd, captured := range [...]bool{1: rl.captureStdout, 2: rl.captureStderr} {
+		if captured {
+			if err := dupFd(f, fd); err != nil {
+				go rl.report(err)
+			}
+		}
+	}
+}
+
+// restoreFdsLocked restores the captured descriptors. rl.mu must be held.
+func (rl *LogRot) restoreFdsLocked() {
+	for fd, saved := range [...]**os.File{1: &rl.savedStdout, 2: &rl.savedStderr} {
+		if saved == nil || *saved == nil {
+			continue
+		}
+		dupFd(*saved, fd)
+		(*saved).Close()
+		*saved = nil
+	}
+	rl.captureStdout, rl.captureStderr = false, false
+}
@@ -0,0 +1,126 @@
+package logrot
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A Compressor compresses backups made by rotation.
+type Compressor interface {
+	// Ext returns the extension added to the names of compressed backups,
+	// e.g. ".gz".
+	Ext() string
+	// NewWriter returns a writer that compresses to w. Closing it must
+	// flush the compressed data but not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// Gzip returns a Compressor that writes gzip files at the given
+// compression level of package compress/gzip.
+func Gzip(level int) Compressor {
+	return gzipCompressor(level)
+}
+
+type gzipCompressor int
+
+func (gzipCompressor) Ext() string { return ".gz" }
+
+func (c gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, int(c))
+}
+
+// WithCompression compresses each backup made by rotation with c, in the
+// background, once writing has moved on to the new file. The compressed
+// backup replaces the original only when it is complete. If compression
+// fails the original is kept, unless WithDropOnCompressError is given.
+func WithCompression(c Compressor) Option {
+	return func(rl *LogRot) {
+		rl.compressor = c
+	}
+}
+
+// WithDropOnCompressError removes a backup that could not be compressed
+// instead of keeping it uncompressed.
+func WithDropOnCompressError() Option {
+	return func(rl *LogRot) {
+		rl.dropOnCompressError = true
+	}
+}
+
+// compressExt returns the extension of compressed backups, if any.
+func (rl *LogRot) compressExt() string {
+	if rl.compressor == nil {
+		return ""
+	}
+	return rl.compressor.Ext()
+}
+
+// compress replaces the backup with a compressed copy that keeps its
+// modification time. The copy is written to a temporary file and renamed
+// into place, so a crash leaves either the original or both.
+func (rl *LogRot) compress(backup string) {
+	if rl.compressor == nil {
+		return
+	}
+	err := rl.compressFile(backup, backup+rl.compressor.Ext())
+	if err == nil {
+		os.Remove(backup)
+		return
+	}
+	rl.report(fmt.Errorf("compressing %s: %v", backup, err))
+	if rl.dropOnCompressError {
+		os.Remove(backup)
+	}
+}
+
+// compressFile writes src compressed to dst. dst has the mode and owner of
+// the log files, or the mode of src if none is set with WithFileMode.
+func (rl *LogRot) compressFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	out, err := rl.createFile(tmp, os.O_EXCL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(tmp)
+		}
+	}()
+	if rl.fileMode == 0 {
+		if err = out.Chmod(fi.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	w, err := rl.compressor.NewWriter(out)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, in); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(tmp, fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,78 @@
+package logrot
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompress(t *testing.T) {
+	dir := tempDir(t)
+
+	backup := filepath.Join(dir, "app-2026-10-16.log")
+	want := "some log\nmore log\n"
+	if err := ioutil.WriteFile(backup, []byte(want), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rl := &LogRot{name: filepath.Join(dir, "app.log"), compressor: Gzip(gzip.BestSpeed)}
+	rl.compress(backup)
+
+	if exists(backup) {
+		t.Errorf("%s not removed", backup)
+	}
+	f, err := os.Open(backup + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if fi, _ := f.Stat(); fi.Mode().Perm() != 0600 {
+		t.Errorf("mode %v; want 0600", fi.Mode().Perm())
+	}
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if name := rl.backupName(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "2006-01-02"); filepath.Base(name) != "app-2026-10-16-1.log" {
+		t.Errorf("backupName = %s; want app-2026-10-16-1.log", name)
+	}
+}
+
+type failingCompressor struct{}
+
+func (failingCompressor) Ext() string { return ".fail" }
+
+func (failingCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nil, errors.New("no compression today")
+}
+
+func TestCompressError(t *testing.T) {
+	dir := tempDir(t)
+
+	for _, drop := range []bool{false, true} {
+		backup := filepath.Join(dir, "app-2026-10-16.log")
+		if err := ioutil.WriteFile(backup, []byte("some log\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		rl := &LogRot{name: filepath.Join(dir, "app.log"), compressor: failingCompressor{}, dropOnCompressError: drop, onError: func(error) {}}
+		rl.compress(backup)
+		if exists(backup) == drop {
+			t.Errorf("drop %v: original kept: %v", drop, !drop)
+		}
+		if fis, _ := ioutil.ReadDir(dir); len(fis) > 1 {
+			t.Errorf("drop %v: %d files left", drop, len(fis))
+		}
+		os.Remove(backup)
+	}
+}
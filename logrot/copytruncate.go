@@ -0,0 +1,53 @@
+package logrot
+
+import (
+	"io"
+	"os"
+)
+
+// WithCopyTruncate rotates by copying the log file to the backup and then
+// truncating it in place, instead of renaming it and reopening name, for
+// programs that follow the file by its inode. The file stays open, in
+// append mode, so writing continues at its new end.
+//
+// Writes through the LogRot wait for the rotation and are never lost. Data
+// written to the file by other means, such as captured stdout and stderr,
+// child processes or other programs, is lost if it lands after the copy
+// read the end of the file and before the truncation: at most what those
+// writers produce in the time a read and a truncate system call take.
+// Signals still reopen name, which is the same file.
+func WithCopyTruncate() Option {
+	return func(rl *LogRot) {
+		rl.copyTruncate = true
+	}
+}
+
+// copyTruncateHook, if not nil, is called between the copy and the
+// truncation of the log file, for tests.
+var copyTruncateHook func()
+
+// copyTruncateLocked copies the log file to backup and truncates it. If
+// the copy fails, the partial backup is removed. rl.mu must be held.
+func (rl *LogRot) copyTruncateLocked(backup string) error {
+	src, err := os.Open(rl.name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := rl.createFile(backup, os.O_EXCL)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(backup)
+		return err
+	}
+	if copyTruncateHook != nil {
+		copyTruncateHook()
+	}
+	return rl.logFile.Truncate(0)
+}
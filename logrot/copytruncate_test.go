@@ -0,0 +1,58 @@
+package logrot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cention-sany/log"
+)
+
+func TestCopyTruncate(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+	backup := filepath.Join(dir, "log-backup.txt")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithCopyTruncate(), WithOnError(func(err error) { t.Error(err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+	before, _ := os.Stat(name)
+
+	// Other writers append to the file directly.
+	other, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	l.Println("copied")
+	done := make(chan struct{})
+	defer func() { copyTruncateHook = nil }()
+	copyTruncateHook = func() {
+		other.WriteString("lost\n")
+		go func() {
+			l.Println("waited")
+			close(done)
+		}()
+	}
+	rl.mu.Lock()
+	file := rl.logFile
+	rl.rotateBackupLocked("size", backup)
+	rl.mu.Unlock()
+	<-done
+	l.Println("after")
+
+	if got, want := readFile(t, backup), "copied\n"; got != want {
+		t.Errorf("backup got %q; want %q", got, want)
+	}
+	if got, want := readFile(t, name), "waited\nafter\n"; got != want {
+		t.Errorf("log file got %q; want %q", got, want)
+	}
+	after, _ := os.Stat(name)
+	if !os.SameFile(before, after) || rl.logFile != file {
+		t.Error("log file replaced")
+	}
+}
@@ -0,0 +1,212 @@
+package logrot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/cention-sany/log"
+)
+
+// A DiskAction is what a LogRot does while the free space on the volume of
+// its file is low, see DiskGuard.
+type DiskAction int
+
+const (
+	// DiskDropDebug raises the level of the loggers of the LogRot and of
+	// the package logger that are log.Levelers to at least InfoLevel, so
+	// that debug entries are dropped.
+	DiskDropDebug DiskAction = iota
+	// DiskDeleteBackups removes the oldest backups until enough space is
+	// free or none is left.
+	DiskDeleteBackups
+	// DiskBuffer keeps what is written in memory, dropping the oldest
+	// writes beyond DiskGuard.BufferSize bytes, and writes one warning to
+	// stderr. The buffer is written to the file when space is free again.
+	DiskBuffer
+)
+
+// A DiskGuard watches the free space on the volume of the log file and
+// takes Action while it is below MinFree. Normal operation resumes once
+// MinFree bytes are free again. It is only supported on Linux.
+type DiskGuard struct {
+	MinFree    uint64 // bytes available to unprivileged users
+	Action     DiskAction
+	Interval   time.Duration // between checks; 0 means 10 seconds
+	BufferSize int           // bytes kept by DiskBuffer; 0 means 1 MiB
+}
+
+// WithDiskGuard checks the free space on the volume of the log file as set
+// by g.
+func WithDiskGuard(g DiskGuard) Option {
+	return func(rl *LogRot) {
+		if g.Interval <= 0 {
+			g.Interval = 10 * time.Second
+		}
+		if g.BufferSize <= 0 {
+			g.BufferSize = 1 << 20
+		}
+		rl.guard = &g
+	}
+}
+
+// freeSpace returns the free space on the volume of a directory; a
+// variable for tests.
+var freeSpace = volumeFree
+
+// diskBuffer is the bounded buffer of DiskBuffer.
+type diskBuffer struct {
+	writes  [][]byte
+	size    int // bytes in writes
+	dropped int // writes dropped to stay within the bound
+}
+
+// checkDisk takes or ends the action of the guard as the free space
+// requires. rl.mu must not be held.
+func (rl *LogRot) checkDisk() {
+	rl.mu.RLock()
+	dir := filepath.Dir(rl.path)
+	low := rl.lowDisk
+	rl.mu.RUnlock()
+	free, err := freeSpace(dir)
+	if err != nil {
+		return
+	}
+	switch {
+	case free < rl.guard.MinFree && !low:
+		rl.enterLowDisk(free)
+	case free < rl.guard.MinFree:
+		if rl.guard.Action == DiskDeleteBackups {
+			rl.deleteOldest(dir)
+		}
+	case low:
+		rl.leaveLowDisk(free)
+	}
+}
+
+func (rl *LogRot) enterLowDisk(free uint64) {
+	msg := fmt.Sprintf("logrot: %d bytes free for %s", free, rl.name)
+	switch rl.guard.Action {
+	case DiskDropDebug:
+		lg.Printf("%s - dropping debug entries\n", msg)
+		rl.mu.Lock()
+		rl.lowDisk = true
+		rl.mu.Unlock()
+		rl.dropDebug()
+	case DiskDeleteBackups:
+		lg.Printf("%s - deleting old backups\n", msg)
+		rl.mu.Lock()
+		rl.lowDisk = true
+		dir := filepath.Dir(rl.path)
+		rl.mu.Unlock()
+		rl.deleteOldest(dir)
+	case DiskBuffer:
+		rl.mu.Lock()
+		rl.lowDisk = true
+		rl.buffer = &diskBuffer{}
+		stderr := rl.stderr
+		if rl.savedStderr != nil {
+			stderr = rl.savedStderr
+		}
+		rl.mu.Unlock()
+		fmt.Fprintf(stderr, "%s - keeping up to %d bytes of log in memory\n", msg, rl.guard.BufferSize)
+	}
+}
+
+func (rl *LogRot) leaveLowDisk(free uint64) {
+	rl.mu.Lock()
+	rl.lowDisk = false
+	rl.flushBufferLocked()
+	rl.mu.Unlock()
+	rl.restoreLevels()
+	lg.Printf("logrot: %d bytes free for %s - resuming normal operation\n", free, rl.name)
+}
+
+// flushBufferLocked writes the buffer of DiskBuffer, if any, to the file
+// and stops buffering. rl.mu must be held.
+func (rl *LogRot) flushBufferLocked() {
+	b := rl.buffer
+	if b == nil {
+		return
+	}
+	rl.buffer = nil
+	if b.dropped > 0 {
+		fmt.Fprintf(rl.logFile, "logrot: dropped %d writes while disk space was low\n", b.dropped)
+	}
+	for _, p := range b.writes {
+		n, _ := rl.logFile.Write(p)
+		atomic.AddInt64(&rl.size, int64(n))
+	}
+}
+
+// bufferLocked adds p to the buffer, dropping the oldest writes beyond its
+// bound. rl.mu must be held for reading at least.
+func (rl *LogRot) bufferLocked(p []byte) {
+	rl.bufferMu.Lock()
+	defer rl.bufferMu.Unlock()
+	b := rl.buffer
+	b.writes = append(b.writes, append([]byte(nil), p...))
+	b.size += len(p)
+	for b.size > rl.guard.BufferSize && len(b.writes) > 0 {
+		b.size -= len(b.writes[0])
+		b.writes[0] = nil
+		b.writes = b.writes[1:]
+		b.dropped++
+	}
+}
+
+// levelers returns the loggers whose level DiskDropDebug raises.
+func (rl *LogRot) levelers() []log.Leveler {
+	var ls []log.Leveler
+	if l, ok := lg.(log.Leveler); ok {
+		ls = append(ls, l)
+	}
+	for _, l := range rl.loggers {
+		if l, ok := l.(log.Leveler); ok {
+			ls = append(ls, l)
+		}
+	}
+	return ls
+}
+
+// dropDebug raises the levels of the loggers to InfoLevel, remembering
+// those it changes.
+func (rl *LogRot) dropDebug() {
+	rl.levels = make(map[log.Leveler]log.LogLevel)
+	for _, l := range rl.levelers() {
+		if lvl := l.Level(); lvl < log.InfoLevel {
+			rl.levels[l] = lvl
+			l.SetLevel(log.InfoLevel)
+		}
+	}
+}
+
+// restoreLevels restores the levels changed by dropDebug.
+func (rl *LogRot) restoreLevels() {
+	for l, lvl := range rl.levels {
+		l.SetLevel(lvl)
+	}
+	rl.levels = nil
+}
+
+// deleteOldest removes the oldest backups until MinFree bytes are free in
+// dir or no backup is left.
+func (rl *LogRot) deleteOldest(dir string) {
+	rl.afterMu.Lock()
+	defer rl.afterMu.Unlock()
+	backups, err := rl.backups()
+	if err != nil {
+		rl.report(err)
+		return
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		if free, err := freeSpace(dir); err != nil || free >= rl.guard.MinFree {
+			return
+		}
+		if err := os.Remove(filepath.Join(rl.backupDir(), backups[i].Name())); err != nil {
+			rl.report(err)
+		}
+	}
+}
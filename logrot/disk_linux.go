@@ -0,0 +1,18 @@
+//go:build linux
+
+package logrot
+
+import (
+	"os"
+	"syscall"
+)
+
+// volumeFree returns the bytes available to unprivileged users on the
+// volume of dir.
+func volumeFree(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, os.NewSyscallError("statfs", err)
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package logrot
+
+import "errors"
+
+func volumeFree(dir string) (uint64, error) {
+	return 0, errors.New("logrot: free space is not known on this platform")
+}
@@ -0,0 +1,131 @@
+package logrot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cention-sany/log"
+)
+
+// fakeFree makes freeSpace return *free until the returned function is
+// called.
+func fakeFree(free *uint64) func() {
+	freeSpace = func(string) (uint64, error) { return *free, nil }
+	return func() { freeSpace = volumeFree }
+}
+
+func TestDiskBuffer(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	stderr, err := ioutil.TempFile(dir, "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+	defer func(f *os.File) { os.Stderr = f }(os.Stderr)
+	os.Stderr = stderr
+
+	free := uint64(10)
+	defer fakeFree(&free)()
+	l := log.New(nil, "", 0)
+	guard := DiskGuard{MinFree: 100, Action: DiskBuffer, Interval: time.Hour, BufferSize: 25}
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithDiskGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	for i := 0; i < 5; i++ {
+		l.Printf("line %04d", i) // 10 bytes with the newline
+	}
+	rl.checkDisk()
+	if got := readFile(t, name); got != "" {
+		t.Errorf("got %q while low on space; want nothing", got)
+	}
+	if got := readFile(t, stderr.Name()); strings.Count(got, "\n") != 1 || !strings.Contains(got, "10 bytes free") {
+		t.Errorf("stderr got %q; want one warning", got)
+	}
+
+	free = 1000
+	rl.checkDisk()
+	l.Println("after")
+	want := "logrot: dropped 3 writes while disk space was low\nline 0003\nline 0004\n"
+	if got := readFile(t, name); !strings.HasPrefix(got, want) || !strings.HasSuffix(got, "after\n") {
+		t.Errorf("got %q; want it to start with %q and end with the line logged after", got, want)
+	}
+}
+
+func TestDiskDropDebug(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	free := uint64(10)
+	defer fakeFree(&free)()
+	defer SetPkgLog(lg)
+	SetPkgLog(log.New(nil, "", 0))
+	l := log.New(nil, "", 0)
+	l.SetLevel(log.DebugLevel)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithDiskGuard(DiskGuard{MinFree: 100, Interval: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	l.Debug("dropped")
+	l.Info("kept")
+	free = 1000
+	rl.checkDisk()
+	if lvl := l.Level(); lvl != log.DebugLevel {
+		t.Errorf("level %v after space was freed; want DEBUG", lvl)
+	}
+	l.Debug("written")
+	got := readFile(t, name)
+	if strings.Contains(got, "dropped") || !strings.Contains(got, "INFO kept") || !strings.Contains(got, "DEBUG written") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDiskDeleteBackups(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	backups := []string{"log-2026-10-14.txt", "log-2026-10-15.txt", "log-2026-10-16.txt"}
+	now := time.Now()
+	for i, b := range backups {
+		path := filepath.Join(dir, b)
+		ioutil.WriteFile(path, []byte("old\n"), 0644)
+		mtime := now.Add(time.Duration(i-len(backups)) * time.Hour)
+		os.Chtimes(path, mtime, mtime)
+	}
+	// Each removed backup frees 10 bytes.
+	freeSpace = func(string) (uint64, error) {
+		var n uint64
+		for _, b := range backups {
+			if !exists(filepath.Join(dir, b)) {
+				n += 10
+			}
+		}
+		return n, nil
+	}
+	defer func() { freeSpace = volumeFree }()
+
+	rl, err := Open(name, WithSignals(), WithDiskGuard(DiskGuard{MinFree: 20, Action: DiskDeleteBackups, Interval: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	for i, b := range backups {
+		if want := i == 2; exists(filepath.Join(dir, b)) != want {
+			t.Errorf("%s exists: %v; want %v", b, !want, want)
+		}
+	}
+	if !exists(name) {
+		t.Error("log file removed")
+	}
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package logrot
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+const fdCaptureSupported = true
+
+// dupFd makes the descriptor fd refer to the file f.
+func dupFd(f *os.File, fd int) error {
+	if err := syscall.Dup3(int(f.Fd()), fd, 0); err != nil {
+		return os.NewSyscallError("dup3", err)
+	}
+	return nil
+}
+
+// saveFd returns a duplicate of the descriptor fd, closed on exec.
+func saveFd(fd int) (*os.File, error) {
+	syscall.ForkLock.RLock()
+	nfd, err := syscall.Dup(fd)
+	if err == nil {
+		syscall.CloseOnExec(nfd)
+	}
+	syscall.ForkLock.RUnlock()
+	if err != nil {
+		return nil, os.NewSyscallError("dup", err)
+	}
+	return os.NewFile(uintptr(nfd), "fd "+strconv.Itoa(fd)), nil
+}
@@ -0,0 +1,56 @@
+//go:build linux
+
+package logrot
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func inode(t *testing.T, fd int) uint64 {
+	var st syscall.Stat_t
+	if err := syscall.Fstat(fd, &st); err != nil {
+		t.Fatal(err)
+	}
+	return st.Ino
+}
+
+func TestFdCapture(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	stdout, stderr := inode(t, 1), inode(t, 2)
+	rl, err := Open(name, WithSignals(), WithFdCapture())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl.CaptureStdout()
+	rl.CaptureStderr()
+
+	syscall.Write(1, []byte("fd 1\n"))
+	syscall.Write(2, []byte("fd 2\n"))
+	if got, want := readFile(t, name), "fd 1\nfd 2\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	os.Rename(name, name+".old")
+	if err := rl.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", "echo child; echo child error >&2")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, name), "child\nchild error\n"; got != want {
+		t.Errorf("after Reopen got %q; want %q", got, want)
+	}
+
+	rl.Close()
+	if inode(t, 1) != stdout || inode(t, 2) != stderr {
+		t.Error("descriptors not restored by Close")
+	}
+}
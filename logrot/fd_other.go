@@ -0,0 +1,20 @@
+//go:build !linux
+
+package logrot
+
+import (
+	"errors"
+	"os"
+)
+
+const fdCaptureSupported = false
+
+var errFdCapture = errors.New("logrot: file descriptor capture is not supported on this platform")
+
+func dupFd(f *os.File, fd int) error {
+	return errFdCapture
+}
+
+func saveFd(fd int) (*os.File, error) {
+	return nil, errFdCapture
+}
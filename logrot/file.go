@@ -0,0 +1,69 @@
+package logrot
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WithFileMode creates the log files with the permissions mode instead of
+// 0644, and sets them on existing files when they are opened, regardless
+// of the umask.
+func WithFileMode(mode os.FileMode) Option {
+	return func(rl *LogRot) {
+		rl.fileMode = mode
+	}
+}
+
+// WithOwner changes the owner and group of the log files to uid and gid
+// whenever they are opened, on the open file, so that it still applies to
+// the file written after the process dropped privileges if it may chown
+// it. An id of -1 is left unchanged. Opening fails if the change does.
+func WithOwner(uid, gid int) Option {
+	return func(rl *LogRot) {
+		rl.uid, rl.gid = uid, gid
+		rl.chown = true
+	}
+}
+
+// WithMkdirAll creates the missing directories of the log files, with the
+// permissions mode, before opening them.
+func WithMkdirAll(mode os.FileMode) Option {
+	return func(rl *LogRot) {
+		rl.dirMode = mode
+	}
+}
+
+// openFile opens the log file name for appending, creating it and its
+// directory as configured.
+func (rl *LogRot) openFile(name string) (*os.File, error) {
+	return rl.createFile(name, os.O_APPEND)
+}
+
+// createFile opens name for writing with the extra flags, which may be
+// os.O_APPEND or os.O_EXCL, and applies the configured mode and owner.
+func (rl *LogRot) createFile(name string, flag int) (*os.File, error) {
+	if rl.dirMode != 0 {
+		if err := os.MkdirAll(filepath.Dir(name), rl.dirMode); err != nil {
+			return nil, err
+		}
+	}
+	mode := os.FileMode(0644)
+	if rl.fileMode != 0 {
+		mode = rl.fileMode
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|flag, mode)
+	if err != nil {
+		return nil, err
+	}
+	if rl.fileMode != 0 {
+		err = f.Chmod(rl.fileMode)
+	}
+	if err == nil && rl.chown {
+		err = f.Chown(rl.uid, rl.gid)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package logrot
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestOwner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner needs root")
+	}
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	rl, err := Open(name, WithSignals(), WithOwner(1234, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+	owner := func(name string) {
+		t.Helper()
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st := fi.Sys().(*syscall.Stat_t); st.Uid != 1234 || st.Gid != 0 {
+			t.Errorf("%s owned by %d:%d; want 1234:0", name, st.Uid, st.Gid)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		owner(name)
+		os.Remove(name)
+		if err := rl.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup := filepath.Join(dir, "log-2026-10-16.txt")
+	ioutil.WriteFile(backup, []byte("old\n"), 0644)
+	rl.compressor = Gzip(gzip.BestSpeed)
+	rl.compress(backup)
+	owner(backup + ".gz")
+}
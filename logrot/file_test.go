@@ -0,0 +1,54 @@
+package logrot
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileMode(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "audit", "log.txt")
+
+	rl, err := Open(name, WithSignals(), WithFileMode(0600), WithMkdirAll(0750))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+	check := func(name string, want os.FileMode) {
+		t.Helper()
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != want {
+			t.Errorf("%s has mode %v; want %v", name, fi.Mode().Perm(), want)
+		}
+	}
+	check(filepath.Dir(name), 0750)
+	check(name, 0600)
+
+	// Reopening applies the mode to an existing file and creates the
+	// directory again.
+	os.RemoveAll(filepath.Dir(name))
+	os.MkdirAll(filepath.Dir(name), 0755)
+	ioutil.WriteFile(name, nil, 0644)
+	if err := rl.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	check(name, 0600)
+	os.RemoveAll(filepath.Dir(name))
+	if err := rl.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	check(filepath.Dir(name), 0750)
+	check(name, 0600)
+
+	backup := filepath.Join(dir, "audit", "log-2026-10-16.txt")
+	ioutil.WriteFile(backup, []byte("old\n"), 0644)
+	rl.compressor = Gzip(gzip.BestSpeed)
+	rl.compress(backup)
+	check(backup+".gz", 0600)
+}
@@ -0,0 +1,83 @@
+package logrot
+
+import (
+	"fmt"
+	"os"
+)
+
+// A Rotation describes a rotation of the log file to the hooks set with
+// WithOnBeforeRotate and WithOnAfterRotate.
+type Rotation struct {
+	// Reason is "size", "schedule", "signal", "moved" for a file moved or
+	// removed by another program, "retry" after a failed rotation,
+	// "reopen" for a call to Reopen or "open" for the first file.
+	Reason string
+	// OldPath is where the previous file is kept: the backup, or the
+	// path it was opened with when reopening.
+	OldPath string
+	// NewPath is the path of the file written after the rotation.
+	NewPath string
+	// Old is the previous file, closed after the OnAfterRotate hooks
+	// return. With WithCopyTruncate Old and New are the same file.
+	Old *os.File
+	// New is the file written after the rotation, nil before it.
+	New *os.File
+}
+
+// WithOnBeforeRotate calls f before each rotation. Like all hooks, f is
+// called with writes through the LogRot blocked: it must not write through
+// it or its loggers, nor take long.
+func WithOnBeforeRotate(f func(*Rotation)) Option {
+	return func(rl *LogRot) {
+		rl.beforeHooks = append(rl.beforeHooks, f)
+	}
+}
+
+// WithOnAfterRotate calls f after each successful rotation, before anything
+// else is written to the new file, which f may write to directly. See
+// WithOnBeforeRotate.
+func WithOnAfterRotate(f func(*Rotation)) Option {
+	return func(rl *LogRot) {
+		rl.afterHooks = append(rl.afterHooks, f)
+	}
+}
+
+// WithHeader writes a line with the host name, process ID, version, reason
+// of the rotation and previous file at the top of each new log file, and
+// of the first one if it is empty, e.g.
+//
+//	logrot: host=web1 pid=4242 version="1.4.2" reason=size previous="/var/log/app-2026-10-16T14-00-00.000.log"
+func WithHeader(version string) Option {
+	return func(rl *LogRot) {
+		rl.header = true
+		rl.version = version
+	}
+}
+
+// beforeHooksLocked calls the OnBeforeRotate hooks. rl.mu must be held.
+func (rl *LogRot) beforeHooksLocked(r *Rotation) {
+	for _, f := range rl.beforeHooks {
+		f(r)
+	}
+}
+
+// afterHooksLocked writes the header to the new file, if it is empty, and
+// calls the OnAfterRotate hooks. The caller sets rl.size after, to count
+// what they wrote. rl.mu must be held.
+func (rl *LogRot) afterHooksLocked(r *Rotation) {
+	if rl.header && fileSize(r.New) == 0 {
+		rl.writeHeader(r)
+	}
+	for _, f := range rl.afterHooks {
+		f(r)
+	}
+}
+
+func (rl *LogRot) writeHeader(r *Rotation) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	fmt.Fprintf(r.New, "logrot: host=%s pid=%d version=%q reason=%s previous=%q\n",
+		host, os.Getpid(), rl.version, r.Reason, r.OldPath)
+}
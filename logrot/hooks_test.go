@@ -0,0 +1,71 @@
+package logrot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cention-sany/log"
+)
+
+func TestRotateHooks(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	var events []string
+	before := func(r *Rotation) {
+		events = append(events, fmt.Sprintf("before %s %s %v", r.Reason, filepath.Base(r.NewPath), r.New == nil))
+	}
+	after := func(r *Rotation) {
+		events = append(events, fmt.Sprintf("after %s %s", r.Reason, filepath.Base(r.NewPath)))
+		fmt.Fprintf(r.Old, "rotated to %s\n", r.NewPath) // still open.
+		fmt.Fprintf(r.New, "rotated from %s\n", r.OldPath)
+	}
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithMaxSize(1000),
+		WithOnBeforeRotate(before), WithOnAfterRotate(after), WithHeader("1.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	header := func(reason, previous string) string {
+		host, _ := os.Hostname()
+		return fmt.Sprintf("logrot: host=%s pid=%d version=\"1.2.3\" reason=%s previous=%q\n", host, os.Getpid(), reason, previous)
+	}
+	if got, want := readFile(t, name), header("open", ""); got != want {
+		t.Errorf("first file got %q; want %q", got, want)
+	}
+
+	line := strings.Repeat("x", 600)
+	l.Print(line)
+	l.Print(line)
+	backups, _ := filepath.Glob(filepath.Join(dir, "log-*.txt"))
+	if len(backups) != 1 {
+		t.Fatalf("got backups %v; want one", backups)
+	}
+	want := header("size", backups[0]) + "rotated from " + backups[0] + "\n" + line + "\n"
+	if got := readFile(t, name); got != want {
+		t.Errorf("new file got %q; want %q", got, want)
+	}
+	if got := readFile(t, backups[0]); !strings.HasSuffix(got, "rotated to "+name+"\n") {
+		t.Errorf("backup got %q; want it to end with the line written by the hook", got)
+	}
+
+	os.Rename(name, name+".old")
+	if err := rl.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	wantEvents := []string{
+		"before size log.txt true", "after size log.txt",
+		"before reopen log.txt true", "after reopen log.txt",
+	}
+	if strings.Join(events, "\n") != strings.Join(wantEvents, "\n") {
+		t.Errorf("got events %q; want %q", events, wantEvents)
+	}
+	if got := readFile(t, name); !strings.HasPrefix(got, header("reopen", name)) {
+		t.Errorf("reopened file got %q; want a header", got)
+	}
+}
@@ -1,11 +1,16 @@
-// Package logrot handles log rotation on SIGHUP.
+// Package logrot handles log rotation on signals, by file size and on a schedule.
 package logrot
 
 import (
+	"errors"
 	"io"
 	"os"
-	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/cention-sany/log"
 )
@@ -20,34 +25,141 @@ type Logger interface {
 	SetOutput(io.Writer)
 }
 
-func mustOpenFileForAppend(name string) *os.File {
-	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		lg.Fatal("Error: ", err)
-	}
-	return f
-}
-
-// LogRot represents log file that will be reopened on a given signal.
+// LogRot represents log file that will be reopened on given signals.
+// Loggers write to the file through the LogRot, which is an io.Writer that
+// stays the same across rotations: their output is set once, and the file
+// is only swapped or closed while no write through the LogRot is in
+// progress.
 type LogRot struct {
 	name    string
-	logFile *os.File
-	signal  os.Signal
+	signals []os.Signal
 	quit    chan struct{}
 
+	mu      sync.RWMutex // held for reading by writes and for writing by everything else
+	closed  bool         // set by Close; no file is opened after
+	logFile *os.File
+	path    string // name of logFile: name, or the active file with WithNaming
+	size    int64  // bytes in logFile, including writes in progress; accessed atomically
+	maxSize int64  // rotate before a write takes logFile past this size; 0 means never
+
+	schedule     Schedule // rotate at the end of each period; zero means never
+	copyTruncate bool
+	naming       string // layout of the names of the files; "" means name
+
+	guard    *DiskGuard // nil means no checks of free space
+	lowDisk  bool       // free space is below guard.MinFree
+	buffer   *diskBuffer
+	bufferMu sync.Mutex                   // protects buffer contents while rl.mu is held for reading
+	levels   map[log.Leveler]log.LogLevel // levels changed by DiskDropDebug
+
+	beforeHooks []func(*Rotation)
+	afterHooks  []func(*Rotation)
+	header      bool
+	version     string
+
+	fileMode os.FileMode // 0 means 0644 on creation
+	dirMode  os.FileMode // 0 means no MkdirAll
+	chown    bool
+	uid, gid int
+
+	watchInterval time.Duration // poll interval of the watcher; 0 means no watcher
+	unwatch       chan struct{} // closed to stop the watcher
+	watchDone     chan struct{} // closed when the watcher stopped
+
+	maxBackups int           // backups to keep; 0 means all
+	maxAge     time.Duration // age of backups to keep; 0 means any
+
+	compressor          Compressor // compresses backups; nil means none
+	dropOnCompressError bool
+
+	afterMu sync.Mutex     // serializes afterRotate
+	after   sync.WaitGroup // running afterRotate calls
+
+	onError        func(error)
+	stderrFallback bool
+	stderr         *os.File   // os.Stderr when the LogRot was opened
+	failures       int        // consecutive failed rotations, retried with backoff
+	failc          chan error // failed rotations to report and retry
+
 	loggers []Logger
 
 	captureStdout bool
 	captureStderr bool
+	fdCapture     bool
+	savedStdout   *os.File // the original descriptors 1 and 2 while captured
+	savedStderr   *os.File
+}
+
+// An Option configures a LogRot created with New.
+type Option func(*LogRot)
+
+// WithLoggers sets the output of the loggers to the LogRot.
+func WithLoggers(loggers ...Logger) Option {
+	return func(rl *LogRot) {
+		rl.loggers = append(rl.loggers, loggers...)
+	}
+}
+
+// WithMaxSize rotates the file when a write through the LogRot would make
+// it larger than bytes: the file is renamed to a backup with the time of
+// rotation in its name, see LogRot.backupName, and name is reopened. Output
+// captured from os.Stdout and os.Stderr bypasses the LogRot and is not
+// counted.
+func WithMaxSize(bytes int64) Option {
+	return func(rl *LogRot) {
+		rl.maxSize = bytes
+	}
+}
+
+// WithSignals reopens the file on the signals sigs instead of SIGHUP. With
+// no signals the file is only reopened by calls to Reopen.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(rl *LogRot) {
+		rl.signals = sigs
+	}
+}
+
+// WithOnError calls f with each error in rotating the file, from a
+// goroutine of the LogRot, instead of logging it through the package
+// logger. f must not block.
+func WithOnError(f func(error)) Option {
+	return func(rl *LogRot) {
+		rl.onError = f
+	}
+}
+
+// WithStderrFallback writes to os.Stderr, as it was when the LogRot was
+// opened, while the file cannot be reopened. By default writing continues
+// to the file open before the failed rotation.
+func WithStderrFallback() Option {
+	return func(rl *LogRot) {
+		rl.stderrFallback = true
+	}
+}
+
+// Open sets the output of the package logger and of the loggers given with
+// WithLoggers to the named file and reopens the file on SIGHUP, or the
+// signals given with WithSignals. It returns an error if the file cannot
+// be opened. When reopening fails later, the error is reported, see
+// WithOnError, and reopening is retried with increasing delays while
+// writing continues.
+func Open(name string, opts ...Option) (*LogRot, error) {
+	return rotateOn(name, opts...)
+}
+
+// New is like Open but exits the program through the package logger if
+// the file cannot be opened.
+func New(name string, opts ...Option) *LogRot {
+	return mustRotateOn(name, opts...)
 }
 
 // WriteTo sets the log output to the given file and reopen the file on SIGHUP.
 func WriteTo(name string, loggers ...Logger) *LogRot {
-	return rotateOn(name, syscall.SIGHUP, loggers...)
+	return mustRotateOn(name, WithLoggers(loggers...))
 }
 
 func WriteToWithLog(name string, l log.OutSetter) *LogRot {
-	return rotateOn(name, syscall.SIGHUP, l)
+	return mustRotateOn(name, WithLoggers(l))
 }
 
 // WriteAllTo sets the log output, os.Stdout and os.Stderr to the given file and reopen the file on SIGHUP.
@@ -62,38 +174,178 @@ func WriteAllToWithLog(name string, l log.OutSetter) *LogRot {
 	return WriteAllTo(name, l)
 }
 
-// rotateOn rotates the log file on the given signals
-func rotateOn(name string, sig os.Signal, loggers ...Logger) *LogRot {
+func mustRotateOn(name string, opts ...Option) *LogRot {
+	rl, err := rotateOn(name, opts...)
+	if err != nil {
+		lg.Fatal("Error: ", err)
+	}
+	return rl
+}
+
+// rotateOn rotates the log file on SIGHUP or the signals given with
+// WithSignals. All LogRots share one signal handler, see register.
+func rotateOn(name string, opts ...Option) (*LogRot, error) {
 	rl := &LogRot{
 		name:    name,
-		signal:  sig,
-		logFile: mustOpenFileForAppend(name),
+		signals: []os.Signal{syscall.SIGHUP},
 		quit:    make(chan struct{}),
-		loggers: loggers,
+		stderr:  os.Stderr,
+		failc:   make(chan error, 1),
+	}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	var f *os.File
+	var err error
+	if rl.naming != "" {
+		start := time.Now()
+		if rl.schedule.every > 0 {
+			start = rl.schedule.start(start)
+		}
+		f, err = rl.openNamed(start)
+	} else {
+		rl.path = name
+		f, err = rl.openFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rl.logFile = f
+	atomic.StoreInt64(&rl.size, fileSize(rl.logFile))
+	if rl.header && fileSize(f) == 0 {
+		rl.writeHeader(&Rotation{Reason: "open", NewPath: rl.path, New: f})
+		atomic.StoreInt64(&rl.size, fileSize(f))
 	}
 	rl.setOutput()
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, sig)
+	register(rl)
+	if rl.guard != nil {
+		rl.checkDisk()
+	}
+	if rl.watchInterval > 0 {
+		rl.startWatch()
+	}
 	go func() {
+		var timer, retry, guard <-chan time.Time
+		var period, next time.Time // start and end of the current period of the schedule
+		if rl.schedule.every > 0 {
+			now := time.Now()
+			period, next = rl.schedule.start(now), rl.schedule.next(now)
+			timer = time.After(next.Sub(now))
+		}
+		if rl.guard != nil {
+			t := time.NewTicker(rl.guard.Interval)
+			defer t.Stop()
+			guard = t.C
+		}
 		for {
 			select {
-			case s := <-sigs:
-				if s == rl.signal {
-					lg.Printf("%s received - rotating log file handle on %s\n", s, rl.name)
-					rl.rotate()
+			case <-guard:
+				rl.checkDisk()
+			case now := <-timer:
+				if now.Before(next) {
+					now = next // the wall clock was set back.
 				}
+				lg.Printf("scheduled rotation of %s\n", rl.name)
+				last := period
+				period, next = rl.schedule.start(now), rl.schedule.next(now)
+				rl.mu.Lock()
+				rl.rotateLocked("schedule", last, period, rl.schedule.layout())
+				rl.mu.Unlock()
+				timer = time.After(next.Sub(time.Now()))
+			case err := <-rl.failc:
+				rl.report(err)
+				rl.mu.Lock()
+				n := rl.failures
+				rl.mu.Unlock()
+				retry = time.After(retryDelay(n))
+			case <-retry:
+				retry = nil
+				rl.reopen("retry")
 			case <-rl.quit:
 				return
 			}
 		}
 	}()
-	return rl
+	return rl, nil
+}
+
+// Delays between attempts to reopen the file after a failed rotation.
+var (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// retryDelay returns the delay before retrying after n consecutive failed
+// rotations: doubling from minRetryDelay up to maxRetryDelay.
+func retryDelay(n int) time.Duration {
+	d := minRetryDelay
+	for i := 1; i < n && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// report reports an error through the callback set with WithOnError or
+// else the package logger. rl.mu must not be held.
+func (rl *LogRot) report(err error) {
+	if rl.onError != nil {
+		rl.onError(err)
+		return
+	}
+	lg.Printf("logrot: %v\n", err)
+}
+
+// failLocked records a failed rotation for the goroutine of the LogRot to
+// report and retry. If the file could not be reopened and stderr is the
+// fallback, writing moves there. rl.mu must be held.
+func (rl *LogRot) failLocked(err error, reopen bool) {
+	rl.failures++
+	if reopen && rl.stderrFallback && rl.logFile != rl.stderr {
+		rl.closeLocked(rl.logFile)
+		rl.logFile = rl.stderr
+		atomic.StoreInt64(&rl.size, 0)
+		rl.setStdLocked()
+	}
+	select {
+	case rl.failc <- err:
+	default: // a failure is already pending; the retry covers this one.
+	}
+}
+
+// closeLocked closes f unless it is the stderr fallback. rl.mu must be held.
+func (rl *LogRot) closeLocked(f *os.File) {
+	if f != rl.stderr {
+		f.Close()
+	}
+}
+
+func fileSize(f *os.File) int64 {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
 }
 
 func (rl *LogRot) setOutput() {
-	lg.SetOutput(rl.logFile)
+	lg.SetOutput(rl)
 	for _, l := range rl.loggers {
-		l.SetOutput(rl.logFile)
+		l.SetOutput(rl)
+	}
+	rl.mu.Lock()
+	rl.setStdLocked()
+	rl.mu.Unlock()
+}
+
+// setStdLocked points the captured os.Stdout and os.Stderr, or descriptors
+// with WithFdCapture, to the current log file. rl.mu must be held.
+func (rl *LogRot) setStdLocked() {
+	if rl.fdCapture {
+		rl.dupStdLocked()
+		return
 	}
 	if rl.captureStdout {
 		os.Stdout = rl.logFile
@@ -103,26 +355,276 @@ func (rl *LogRot) setOutput() {
 	}
 }
 
+// Write writes p to the log file, first rotating it if p would take it
+// past the size set with WithMaxSize. After a failed rotation, size-based
+// rotation waits for the retry. Writes run concurrently, each written by
+// one system call, except those that rotate. After Close it returns
+// os.ErrClosed.
+func (rl *LogRot) Write(p []byte) (int, error) {
+	n := int64(len(p))
+	rl.mu.RLock()
+	if rl.buffer != nil {
+		rl.bufferLocked(p)
+		rl.mu.RUnlock()
+		return len(p), nil
+	}
+	size := atomic.AddInt64(&rl.size, n) // reserve room for p.
+	if rl.maxSize <= 0 || rl.failures > 0 || size <= rl.maxSize || size == n {
+		defer rl.mu.RUnlock()
+		return rl.write(p)
+	}
+	atomic.AddInt64(&rl.size, -n)
+	rl.mu.RUnlock()
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.closed {
+		return 0, os.ErrClosed
+	}
+	if size := atomic.LoadInt64(&rl.size); rl.failures == 0 && size > 0 && size+n > rl.maxSize {
+		now := time.Now()
+		rl.rotateLocked("size", now, now, backupTimeFormat)
+	}
+	atomic.AddInt64(&rl.size, n)
+	return rl.write(p)
+}
+
+// write writes p, for which room is reserved in rl.size, to the log file.
+// rl.mu must be held for reading at least.
+func (rl *LogRot) write(p []byte) (int, error) {
+	n, err := rl.logFile.Write(p)
+	if n < len(p) {
+		atomic.AddInt64(&rl.size, int64(n-len(p)))
+	}
+	return n, err
+}
+
+// Close stops rotating the file, restores what was captured and the levels
+// changed by DiskDropDebug, writes what DiskBuffer kept, waits for the
+// compression and pruning of backups in progress and closes the file.
+// Closing it again has no effect.
 func (rl *LogRot) Close() {
-	if rl != nil && rl.logFile != nil {
+	if rl != nil {
+		rl.mu.Lock()
+		closed := rl.closed
+		rl.closed = true
+		rl.mu.Unlock()
+		if closed {
+			return
+		}
+		unregister(rl)
+		if rl.unwatch != nil {
+			close(rl.unwatch)
+			<-rl.watchDone
+		}
 		rl.quit <- struct{}{}
-		rl.logFile.Close()
+		rl.mu.Lock()
+		rl.restoreFdsLocked()
+		rl.flushBufferLocked()
+		rl.closeLocked(rl.logFile)
+		rl.mu.Unlock()
+		rl.restoreLevels()
+		rl.after.Wait()
 	}
 }
 
-func (rl *LogRot) rotate() {
+// Reopen reopens the named file, as on a signal, typically after another
+// program renamed it. With WithNaming it reopens the active file and links
+// name to it again. If that fails, writing continues to the current file
+// or stderr, see WithStderrFallback, and the error is also reported and
+// retried as after a signal. After Close it only returns an error.
+func (rl *LogRot) Reopen() error {
+	return rl.reopen("reopen")
+}
+
+// errClosed is returned by Reopen after Close.
+var errClosed = errors.New("logrot: closed")
+
+// reopen is Reopen for the reason of a Rotation.
+func (rl *LogRot) reopen(reason string) error {
+	rl.mu.RLock()
+	path, closed := rl.path, rl.closed
+	rl.mu.RUnlock()
+	if closed {
+		return errClosed
+	}
+	newLog, err := rl.openFile(path)
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.closed { // closed meanwhile, e.g. on a signal.
+		if newLog != nil {
+			newLog.Close()
+		}
+		return errClosed
+	}
+	if err != nil {
+		rl.failLocked(err, true)
+		return err
+	}
+	if path != rl.path { // rotated meanwhile.
+		newLog.Close()
+		return nil
+	}
+	r := &Rotation{Reason: reason, OldPath: path, NewPath: path, Old: rl.logFile}
+	rl.beforeHooksLocked(r)
 	oldLog := rl.logFile
-	rl.logFile = mustOpenFileForAppend(rl.name)
-	rl.setOutput()
-	oldLog.Close()
+	rl.logFile = newLog
+	rl.failures = 0
+	rl.setStdLocked()
+	r.New = newLog
+	rl.afterHooksLocked(r)
+	atomic.StoreInt64(&rl.size, fileSize(newLog))
+	rl.closeLocked(oldLog)
+	if rl.naming != "" {
+		if err := rl.link(); err != nil {
+			rl.failLocked(err, false)
+			return err
+		}
+	}
+	return nil
 }
 
+// rotateLocked starts a new file at t and keeps the current one, of the
+// time last formatted with layout unless WithNaming is given, as a backup.
+// reason is that of the Rotation. It does nothing after Close. rl.mu must
+// be held.
+func (rl *LogRot) rotateLocked(reason string, last, t time.Time, layout string) {
+	if rl.closed {
+		return
+	}
+	if rl.naming != "" {
+		rl.switchLocked(reason, t)
+		return
+	}
+	rl.rotateBackupLocked(reason, rl.backupName(last, layout))
+}
+
+// renameHook, if not nil, is called between the rename of the log file to
+// the backup and the opening of the new one, for tests.
+var renameHook func()
+
+// rotateBackupLocked renames the log file to backup and reopens name, or
+// copies and truncates it, see WithCopyTruncate. If either step fails,
+// writing continues to the current file or stderr, see failLocked. rl.mu
+// must be held; nothing may be logged through lg, which may write to rl.
+func (rl *LogRot) rotateBackupLocked(reason, backup string) {
+	r := &Rotation{Reason: reason, OldPath: backup, NewPath: rl.name, Old: rl.logFile}
+	if rl.copyTruncate {
+		if rl.logFile == rl.stderr {
+			return // falling back to stderr, which is not the log file.
+		}
+		rl.beforeHooksLocked(r)
+		if err := rl.copyTruncateLocked(backup); err != nil {
+			rl.failLocked(err, false)
+			return
+		}
+		rl.failures = 0
+		r.New = rl.logFile
+		rl.afterHooksLocked(r)
+		atomic.StoreInt64(&rl.size, fileSize(rl.logFile))
+		rl.afterRotateLocked(backup)
+		return
+	}
+	rl.beforeHooksLocked(r)
+	if err := os.Rename(rl.name, backup); err != nil {
+		rl.failLocked(err, false)
+		return
+	}
+	if renameHook != nil {
+		renameHook()
+	}
+	newLog, err := rl.openFile(rl.name)
+	if err != nil {
+		rl.failLocked(err, true)
+		return
+	}
+	oldLog := rl.logFile
+	rl.logFile = newLog
+	rl.failures = 0
+	rl.setStdLocked()
+	r.New = newLog
+	rl.afterHooksLocked(r)
+	atomic.StoreInt64(&rl.size, fileSize(newLog))
+	rl.closeLocked(oldLog)
+	rl.afterRotateLocked(backup)
+}
+
+// afterRotateLocked starts afterRotate in the background. rl.mu must be
+// held.
+func (rl *LogRot) afterRotateLocked(backup string) {
+	rl.after.Add(1)
+	go rl.afterRotate(backup)
+}
+
+// afterRotate compresses the backup and removes old backups.
+func (rl *LogRot) afterRotate(backup string) {
+	defer rl.after.Done()
+	rl.afterMu.Lock()
+	defer rl.afterMu.Unlock()
+	rl.compress(backup)
+	rl.prune()
+}
+
+// backupTimeFormat is the layout of the time in the names of backups made
+// by size-based rotation.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// backupName returns the name of a backup of the log file for time t,
+// which does not exist yet, compressed or not: t formatted with layout is
+// inserted before the extension, followed by a sequence number if needed,
+// e.g. app-2006-01-02T15-04-05.000.log or app-2006-01-02T15-04-05.000-1.log.
+func (rl *LogRot) backupName(t time.Time, layout string) string {
+	ext := filepath.Ext(rl.name)
+	return rl.uniqueName(rl.name[:len(rl.name)-len(ext)]+"-"+t.Format(layout), ext)
+}
+
+// uniqueName returns base+ext, or base-1+ext, base-2+ext and so on: the
+// first that is the name of no file, compressed or not.
+func (rl *LogRot) uniqueName(base, ext string) string {
+	name := base + ext
+	for i := 1; ; i++ {
+		if !exists(name) && !exists(name+rl.compressExt()) {
+			return name
+		}
+		name = base + "-" + strconv.Itoa(i) + ext
+	}
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return !os.IsNotExist(err)
+}
+
+// CaptureStdout points os.Stdout to the log file, again on every rotation.
+// Goroutines that use os.Stdout while the file is rotated may still write
+// to the old file, or fail to once it is closed; WithFdCapture avoids this
+// by leaving os.Stdout alone.
 func (rl *LogRot) CaptureStdout() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.fdCapture && !rl.captureFdLocked(1, &rl.savedStdout) {
+		return
+	}
 	rl.captureStdout = true
-	os.Stdout = rl.logFile
+	rl.setStdLocked()
 }
 
+// CaptureStderr points os.Stderr to the log file like CaptureStdout.
 func (rl *LogRot) CaptureStderr() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.fdCapture && !rl.captureFdLocked(2, &rl.savedStderr) {
+		return
+	}
 	rl.captureStderr = true
-	os.Stderr = rl.logFile
+	rl.setStdLocked()
 }
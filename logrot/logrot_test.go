@@ -5,7 +5,11 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime/debug"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -31,6 +35,17 @@ func readFile(t *testing.T, name string) string {
 	return string(buf)
 }
 
+// tempDir creates a directory for the files of a test, removed when the
+// test ends.
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "logrot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
 func TestWriteTo(t *testing.T) {
 	defer remove(t, "log.txt")
 	os.Remove("log.txt")
@@ -156,7 +171,7 @@ From stderr 2
 		t.Errorf("log file renamed failed\nwant: '%s'\n got: '%v'", want, got)
 	}
 
-	rl.rotate()
+	rl.Reopen()
 	want = `This is after rotation
 LOG Yeah after rotation
 From stdout after rotation
@@ -202,7 +217,7 @@ func TestRotateWithLog(t *testing.T) {
 		t.Errorf("log file renamed failed\nwant: '%s'\n got: '%v'", want, got)
 	}
 
-	rl.rotate()
+	rl.Reopen()
 	want = "This is after rotation\n"
 	l.Printf(want)
 
@@ -249,7 +264,7 @@ func TestSignal(t *testing.T) {
 	<-time.After(1 * time.Second)
 	log.Println("after HUP")
 
-	want = "after HUP\n"
+	want = "hangup received - reopened log files: log.txt ok\nafter HUP\n"
 	got = readFile(t, "log.txt")
 
 	if got != want {
@@ -265,3 +280,277 @@ func TestCloseImmediately(t *testing.T) {
 	rl.Close()
 
 }
+
+func TestMaxSize(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	l := log.New(nil, "", 0)
+	rl := New(name, WithLoggers(l), WithMaxSize(25))
+	defer rl.Close()
+
+	const goroutines, lines = 4, 25
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				l.Printf("%d-%07d", g, i) // 10 bytes with the newline
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "log-*.txt"))
+	var all []string
+	for _, f := range append(backups, name) {
+		got := readFile(t, f)
+		if len(got) > 25 {
+			t.Errorf("%s has %d bytes; want at most 25", f, len(got))
+		}
+		all = append(all, strings.Split(strings.TrimSuffix(got, "\n"), "\n")...)
+	}
+	if len(all) != goroutines*lines {
+		t.Errorf("got %d lines in %d files; want %d", len(all), len(backups)+1, goroutines*lines)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithMaxSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Println("before")
+	rl.Close()
+	if _, err := rl.Write([]byte("after Close\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close returned %v; want %v", err, os.ErrClosed)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 1 {
+		t.Errorf("got files %v; want only %s", files, name)
+	}
+	if got := readFile(t, name); got != "before\n" {
+		t.Errorf("got %q; want the line logged before Close", got)
+	}
+}
+
+func TestOpenError(t *testing.T) {
+	rl, err := Open(filepath.Join("no such dir", "log.txt"))
+	if err == nil {
+		rl.Close()
+		t.Fatal("Open succeeded in a missing directory")
+	}
+}
+
+// testReopenError opens a file in a directory, removes the directory and
+// reopens, then restores the directory and waits for the retry. It returns
+// what is in the reopened file.
+func testReopenError(t *testing.T, opts ...Option) string {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "sub", "log.txt")
+	os.Mkdir(filepath.Dir(name), 0755)
+
+	defer func(d time.Duration) { minRetryDelay = d }(minRetryDelay)
+	minRetryDelay = 10 * time.Millisecond
+	errs := make(chan error, 10)
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, append(opts, WithLoggers(l), WithOnError(func(err error) { errs <- err }))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	os.RemoveAll(filepath.Dir(name))
+	rl.Reopen()
+	select {
+	case err := <-errs:
+		if !os.IsNotExist(err) {
+			t.Errorf("got error %v; want a missing directory", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no error reported")
+	}
+	l.Println("while failed")
+
+	os.Mkdir(filepath.Dir(name), 0755)
+	for i := 0; i < 100; i++ {
+		rl.mu.Lock()
+		n := rl.failures
+		rl.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.Println("after retry")
+	return readFile(t, name)
+}
+
+func TestReopenError(t *testing.T) {
+	if got := testReopenError(t); got != "after retry\n" {
+		t.Errorf("got %q; want only the line logged after the retry", got)
+	}
+}
+
+func TestStderrFallback(t *testing.T) {
+	f, err := ioutil.TempFile("", "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+
+	if got := testReopenError(t, WithStderrFallback()); got != "after retry\n" {
+		t.Errorf("got %q; want only the line logged after the retry", got)
+	}
+	if got := readFile(t, f.Name()); got != "while failed\n" {
+		t.Errorf("stderr got %q; want the line logged while failed", got)
+	}
+}
+
+func TestWithSignals(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(syscall.SIGUSR1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	rl.mu.Lock()
+	old := rl.logFile
+	rl.mu.Unlock()
+	os.Rename(name, name+".old")
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	for i := 0; i < 100; i++ {
+		rl.mu.Lock()
+		reopened := rl.logFile != old
+		rl.mu.Unlock()
+		if reopened {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.Println("after USR1")
+	if got := readFile(t, name); !strings.HasSuffix(got, " reopened log files: "+name+" ok\nafter USR1\n") {
+		t.Errorf("got %q; want the line logged after SIGUSR1", got)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	l.Println("before")
+	os.Rename(name, name+".old")
+	if err := rl.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Println("after")
+	if got := readFile(t, name); got != "after\n" {
+		t.Errorf("got %q; want the line logged after Reopen", got)
+	}
+	if got := readFile(t, name+".old"); got != "before\n" {
+		t.Errorf("old file got %q; want the line logged before Reopen", got)
+	}
+}
+
+func TestReopenAfterClose(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	rl, err := Open(name, WithSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl.Close()
+	os.Remove(name)
+	// As a signal relayed before Close unregistered rl.
+	if err := rl.reopen("signal"); err != errClosed {
+		t.Errorf("reopen after Close returned %v; want %v", err, errClosed)
+	}
+	if exists(name) {
+		t.Error("reopen after Close created the file")
+	}
+}
+
+func TestRotateStress(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	loggers := []*log.Logger{log.New(nil, "", 0), log.New(nil, "", 0), log.New(nil, "", 0)}
+	rl, err := Open(name, WithLoggers(loggers[0], loggers[1], loggers[2]), WithSignals(), WithMaxSize(4096),
+		WithOnError(func(error) {})) // size rotation fails while name is renamed.
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	const goroutines, lines = 16, 500
+	done := make(chan struct{})
+	rotated := make(chan int)
+	go func() {
+		n := 0
+		for {
+			select {
+			case <-done:
+				rotated <- n
+				return
+			default:
+			}
+			os.Rename(name, fmt.Sprintf("%s.%d", name, n))
+			if err := rl.Reopen(); err != nil {
+				t.Error(err)
+			}
+			n++
+		}
+	}()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			l := loggers[g%len(loggers)]
+			for i := 0; i < lines; i++ {
+				l.Printf("%02d-%07d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(done)
+	t.Logf("%d reopens", <-rotated)
+
+	files, _ := filepath.Glob(name + "*")
+	backups, _ := filepath.Glob(filepath.Join(dir, "log-*.txt"))
+	seen := make(map[string]bool)
+	for _, f := range append(files, backups...) {
+		for _, line := range strings.Split(strings.TrimSuffix(readFile(t, f), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			if len(line) != 10 || seen[line] {
+				t.Fatalf("%s: bad or repeated line %q", f, line)
+			}
+			seen[line] = true
+		}
+	}
+	if len(seen) != goroutines*lines {
+		t.Errorf("got %d lines; want %d", len(seen), goroutines*lines)
+	}
+}
@@ -0,0 +1,142 @@
+package logrot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// WithNaming writes to files named after the time with layout, a time
+// layout as in package time, e.g. "app-20060102-1504.log", and makes name
+// a symbolic link to the active one. Only the last element of layout is
+// formatted; a relative layout is relative to the directory of name.
+//
+// The active file is named after the time it was opened, or the start of
+// the period of the schedule. A rotation opens a new file, named with a
+// sequence number if one of that name exists, e.g.
+// app-20261016-1400-1.log, and points the link to it, replacing the link
+// atomically. The previous files are the backups, and WithCopyTruncate has
+// no effect.
+//
+// The file name must not contain other text that reads as part of a
+// layout, such as "Jan" or digits. name must be a symbolic link or not
+// exist. Backups are pruned, see WithMaxBackups, in the directory of the
+// layout.
+func WithNaming(layout string) Option {
+	return func(rl *LogRot) {
+		rl.naming = layout
+	}
+}
+
+// namingLayout returns the layout of the names of the files, as a path.
+func (rl *LogRot) namingLayout() string {
+	if filepath.IsAbs(rl.naming) {
+		return rl.naming
+	}
+	return filepath.Join(filepath.Dir(rl.name), rl.naming)
+}
+
+// activeName returns the name of the file to write from t on. If unique,
+// the name is of no existing file, compressed or not.
+func (rl *LogRot) activeName(t time.Time, unique bool) string {
+	layout := rl.namingLayout()
+	name := filepath.Join(filepath.Dir(layout), t.Format(filepath.Base(layout)))
+	if !unique {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return rl.uniqueName(name[:len(name)-len(ext)], ext)
+}
+
+// openNamed opens the active file for t, appending to it if it exists, and
+// links name to it, when the LogRot is opened.
+func (rl *LogRot) openNamed(t time.Time) (*os.File, error) {
+	if fi, err := os.Lstat(rl.name); err == nil && fi.Mode()&os.ModeSymlink == 0 {
+		return nil, errors.New("logrot: " + rl.name + " exists and is not a symbolic link")
+	}
+	rl.path = rl.activeName(t, false)
+	f, err := rl.openFile(rl.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.link(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// link atomically points name to the active file, by renaming a new link
+// over it.
+func (rl *LogRot) link() error {
+	target := rl.path
+	if rel, err := filepath.Rel(filepath.Dir(rl.name), rl.path); err == nil && !strings.HasPrefix(rel, "..") {
+		target = rel
+	}
+	if cur, err := os.Readlink(rl.name); err == nil && cur == target {
+		return nil
+	}
+	tmp := rl.name + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, rl.name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// switchLocked writes to a new active file for t and links name to it. The
+// previous file is the backup. reason is that of the Rotation. rl.mu must
+// be held.
+func (rl *LogRot) switchLocked(reason string, t time.Time) {
+	path := rl.activeName(t, true)
+	r := &Rotation{Reason: reason, OldPath: rl.path, NewPath: path, Old: rl.logFile}
+	rl.beforeHooksLocked(r)
+	newLog, err := rl.openFile(path)
+	if err != nil {
+		rl.failLocked(err, true)
+		return
+	}
+	backup := rl.path
+	oldLog := rl.logFile
+	rl.path = path
+	rl.logFile = newLog
+	rl.failures = 0
+	rl.setStdLocked()
+	r.New = newLog
+	rl.afterHooksLocked(r)
+	atomic.StoreInt64(&rl.size, fileSize(newLog))
+	rl.closeLocked(oldLog)
+	if err := rl.link(); err != nil {
+		rl.failLocked(err, false) // the retry links again.
+	}
+	rl.afterRotateLocked(backup)
+}
+
+// isNamed reports whether path is the name of a file written with
+// WithNaming, possibly compressed with the extension compressExt.
+func isNamed(layout, path, compressExt string) bool {
+	if compressExt != "" {
+		path = strings.TrimSuffix(path, compressExt)
+	}
+	ext := filepath.Ext(layout)
+	if !strings.HasSuffix(path, ext) {
+		return false
+	}
+	layout, path = layout[:len(layout)-len(ext)], path[:len(path)-len(ext)]
+	if _, err := time.Parse(layout, path); err == nil {
+		return true
+	}
+	i := strings.LastIndexByte(path, '-')
+	if i < 0 || !isDigits(path[i+1:]) {
+		return false
+	}
+	_, err := time.Parse(layout, path[:i])
+	return err == nil
+}
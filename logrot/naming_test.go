@@ -0,0 +1,92 @@
+package logrot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cention-sany/log"
+)
+
+func TestIsNamed(t *testing.T) {
+	const layout = "app-20060102-1504.log"
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"app-20261016-1400.log", true},
+		{"app-20261016-1400-2.log", true},
+		{"app-20261016-1400.log.gz", true},
+		{"app-20261016-1400-.log", false},
+		{"app-20261016.log", false},
+		{"app.log", false},
+		{"app-20261016-1400.log.tmp", false},
+	}
+	for _, tt := range tests {
+		if got := isNamed(layout, tt.name, ".gz"); got != tt.want {
+			t.Errorf("isNamed(%q) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "app.log")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithNaming("app-20060102-150405.log"),
+		WithMaxSize(20), WithMaxBackups(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	const lines = 10
+	for i := 0; i < lines; i++ {
+		l.Printf("line %04d", i) // 10 bytes with the newline
+	}
+	rl.afterMu.Lock()
+	rl.prune()
+	rl.afterMu.Unlock()
+
+	target, err := os.Readlink(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.IsAbs(target) || !isNamed("app-20060102-150405.log", target, "") {
+		t.Errorf("link to %q; want a relative link to an active file", target)
+	}
+	if got, want := readFile(t, name), "line 0008\nline 0009\n"; got != want {
+		t.Errorf("active file got %q; want %q", got, want)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(files) != 3 {
+		t.Errorf("got files %v; want the active file and 2 backups", files)
+	}
+	var all []string
+	for _, f := range files {
+		all = append(all, readFile(t, f))
+	}
+	if got := strings.Join(all, ""); strings.Count(got, "\n") != 6 {
+		t.Errorf("got %q; want the last 6 lines", got)
+	}
+	if exists(name + ".tmp") {
+		t.Error("temporary link left")
+	}
+}
+
+func TestNamingRegularFile(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "app.log")
+	ioutil.WriteFile(name, []byte("old log\n"), 0644)
+
+	if rl, err := Open(name, WithNaming("app-20060102.log")); err == nil {
+		rl.Close()
+		t.Fatal("Open replaced a regular file with a link")
+	}
+	if got := readFile(t, name); got != "old log\n" {
+		t.Errorf("got %q; want the file left alone", got)
+	}
+}
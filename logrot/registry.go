@@ -0,0 +1,121 @@
+package logrot
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+)
+
+// registry relays signals to the open LogRots: one signal channel for the
+// process, listening to the signals of every registered LogRot.
+var registry struct {
+	mu   sync.Mutex
+	rots []*LogRot         // in the order they were opened
+	sigs map[os.Signal]int // number of rots reopened on each signal
+	c    chan os.Signal    // nil while no signals are relayed
+}
+
+// register reopens rl on its signals from now on.
+func register(rl *LogRot) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.rots = append(registry.rots, rl)
+	if len(rl.signals) == 0 {
+		return
+	}
+	if registry.sigs == nil {
+		registry.sigs = make(map[os.Signal]int)
+	}
+	for _, s := range rl.signals {
+		registry.sigs[s]++
+	}
+	if registry.c == nil {
+		registry.c = make(chan os.Signal, 1)
+		go relay(registry.c)
+	}
+	signal.Notify(registry.c, rl.signals...) // adding signals leaves no gap.
+}
+
+// unregister stops reopening rl, if it is registered. The signals no other
+// LogRot is reopened on are no longer relayed, so they have their default
+// effect again unless the program listens to them itself.
+func unregister(rl *LogRot) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	found := false
+	for i, r := range registry.rots {
+		if r == rl {
+			registry.rots = append(registry.rots[:i], registry.rots[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+	var stopped bool
+	for _, s := range rl.signals {
+		if registry.sigs[s]--; registry.sigs[s] <= 0 {
+			delete(registry.sigs, s)
+			stopped = true
+		}
+	}
+	if !stopped {
+		return
+	}
+	// A channel cannot stop relaying some of its signals: listen to the
+	// remaining ones on a new channel before stopping the old one, so that
+	// none of them takes its default effect meanwhile.
+	old := registry.c
+	registry.c = nil
+	if len(registry.sigs) > 0 {
+		sigs := make([]os.Signal, 0, len(registry.sigs))
+		for s := range registry.sigs {
+			sigs = append(sigs, s)
+		}
+		registry.c = make(chan os.Signal, 1)
+		signal.Notify(registry.c, sigs...)
+		go relay(registry.c)
+	}
+	if old != nil {
+		signal.Stop(old)
+		close(old)
+	}
+}
+
+// relay reopens the LogRots on the signals received on c until c is
+// closed.
+func relay(c chan os.Signal) {
+	for s := range c {
+		reopenAll(s)
+	}
+}
+
+// reopenAll reopens the LogRots on signal s in the order they were opened
+// and logs one line with the outcome for each file.
+func reopenAll(s os.Signal) {
+	registry.mu.Lock()
+	var rots []*LogRot
+	for _, rl := range registry.rots {
+		for _, rs := range rl.signals {
+			if rs == s {
+				rots = append(rots, rl)
+				break
+			}
+		}
+	}
+	registry.mu.Unlock()
+	if len(rots) == 0 {
+		return
+	}
+	results := make([]string, len(rots))
+	for i, rl := range rots {
+		if err := rl.reopen("signal"); err != nil {
+			results[i] = rl.name + " failed: " + err.Error()
+		} else {
+			results[i] = rl.name + " ok"
+		}
+	}
+	lg.Printf("%s received - reopened log files: %s\n", s, strings.Join(results, ", "))
+}
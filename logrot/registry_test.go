@@ -0,0 +1,122 @@
+package logrot
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cention-sany/log"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRegistry(t *testing.T) {
+	dir := tempDir(t)
+
+	var buf syncBuffer
+	defer SetPkgLog(lg)
+	pkgLog := log.New(nil, "", 0)
+	SetPkgLog(pkgLog)
+
+	names := []string{"b.log", "a.log", "c.log"}
+	var rots []*LogRot
+	for _, name := range names {
+		rl, err := Open(filepath.Join(dir, name), WithSignals(syscall.SIGUSR2), WithOnError(func(error) {}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rots = append(rots, rl)
+	}
+	other, err := Open(filepath.Join(dir, "other.log"), WithSignals(syscall.SIGUSR1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgLog.SetOutput(&buf)
+	os.Remove(filepath.Join(dir, "a.log"))
+	os.Mkdir(filepath.Join(dir, "a.log"), 0755) // a.log cannot be reopened.
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	var got string
+	for i := 0; i < 100 && got == ""; i++ {
+		time.Sleep(10 * time.Millisecond)
+		got = buf.String()
+	}
+	want := "user defined signal 2 received - reopened log files: " +
+		filepath.Join(dir, "b.log") + " ok, " +
+		filepath.Join(dir, "a.log") + " failed: open " + filepath.Join(dir, "a.log") + ": is a directory, " +
+		filepath.Join(dir, "c.log") + " ok\n"
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	for _, rl := range rots {
+		rl.Close()
+	}
+	registry.mu.Lock()
+	if len(registry.rots) != 1 || len(registry.sigs) != 1 || registry.c == nil {
+		t.Errorf("registry holds %d LogRots on %d signals; want only the one on SIGUSR1", len(registry.rots), len(registry.sigs))
+	}
+	registry.mu.Unlock()
+	other.Close()
+	registry.mu.Lock()
+	if len(registry.rots) != 0 || len(registry.sigs) != 0 || registry.c != nil {
+		t.Errorf("registry not empty after Close: %d LogRots on %d signals", len(registry.rots), len(registry.sigs))
+	}
+	registry.mu.Unlock()
+}
+
+func TestCloseTwice(t *testing.T) {
+	dir := tempDir(t)
+	a, err := Open(filepath.Join(dir, "a.log"), WithSignals(syscall.SIGUSR1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(filepath.Join(dir, "b.log"), WithSignals(syscall.SIGUSR1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		a.Close()
+		a.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("second Close blocked")
+	}
+	registry.mu.Lock()
+	if len(registry.rots) != 1 || registry.sigs[syscall.SIGUSR1] != 1 || registry.c == nil {
+		t.Errorf("registry holds %d LogRots, %d on SIGUSR1; want b only", len(registry.rots), registry.sigs[syscall.SIGUSR1])
+	}
+	registry.mu.Unlock()
+
+	// b is still reopened on the signal, which must not kill the process.
+	b.mu.Lock()
+	old := b.logFile
+	b.mu.Unlock()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	waitReopen(t, b, old)
+}
@@ -0,0 +1,137 @@
+package logrot
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithMaxBackups keeps at most n backups made by rotation, removing the
+// oldest ones after each rotation. Zero means no limit.
+func WithMaxBackups(n int) Option {
+	return func(rl *LogRot) {
+		rl.maxBackups = n
+	}
+}
+
+// WithMaxAge removes backups made by rotation that were last written more
+// than d ago, checking after each rotation. Zero means no limit.
+func WithMaxAge(d time.Duration) Option {
+	return func(rl *LogRot) {
+		rl.maxAge = d
+	}
+}
+
+// backupLayouts are the layouts of the times in backup names.
+var backupLayouts = []string{
+	backupTimeFormat,
+	"2006-01-02",
+	"2006-01-02T15",
+	"2006-01-02T15-04",
+	"2006-01-02T15-04-05",
+}
+
+// isBackup reports whether base, a file name without directory, is the
+// name of a backup of the log file named logBase as made by backupName,
+// possibly compressed with the extension compressExt.
+func isBackup(logBase, base, compressExt string) bool {
+	if compressExt != "" {
+		base = strings.TrimSuffix(base, compressExt)
+	}
+	ext := filepath.Ext(logBase)
+	prefix := logBase[:len(logBase)-len(ext)] + "-"
+	if !strings.HasPrefix(base, prefix) || !strings.HasSuffix(base, ext) ||
+		len(base) < len(prefix)+len(ext) {
+		return false
+	}
+	stamp := base[len(prefix) : len(base)-len(ext)]
+	if parsesAsBackupTime(stamp) {
+		return true
+	}
+	// Strip a sequence number.
+	i := strings.LastIndexByte(stamp, '-')
+	if i < 0 || !isDigits(stamp[i+1:]) {
+		return false
+	}
+	return parsesAsBackupTime(stamp[:i])
+}
+
+func parsesAsBackupTime(s string) bool {
+	for _, layout := range backupLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// backupDir returns the directory of the backups.
+func (rl *LogRot) backupDir() string {
+	if rl.naming != "" {
+		return filepath.Dir(rl.namingLayout())
+	}
+	return filepath.Dir(rl.name)
+}
+
+// backups returns the backups of the log file, newest first.
+func (rl *LogRot) backups() ([]os.FileInfo, error) {
+	f, err := os.Open(rl.backupDir())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fis, err := f.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	isBackup := func(base string) bool {
+		return isBackup(filepath.Base(rl.name), base, rl.compressExt())
+	}
+	if rl.naming != "" {
+		layout := filepath.Base(rl.namingLayout())
+		isBackup = func(base string) bool {
+			return isNamed(layout, base, rl.compressExt())
+		}
+	}
+	rl.mu.RLock()
+	active := filepath.Base(rl.path)
+	rl.mu.RUnlock()
+	var backups []os.FileInfo
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() && fi.Name() != active && isBackup(fi.Name()) {
+			backups = append(backups, fi)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		ti, tj := backups[i].ModTime(), backups[j].ModTime()
+		if ti.Equal(tj) {
+			return backups[i].Name() > backups[j].Name()
+		}
+		return ti.After(tj)
+	})
+	return backups, nil
+}
+
+// prune removes the backups beyond the limits set with WithMaxBackups and
+// WithMaxAge. It must not be called with rl.mu held.
+func (rl *LogRot) prune() {
+	if rl.maxBackups <= 0 && rl.maxAge <= 0 {
+		return
+	}
+	backups, err := rl.backups()
+	if err != nil {
+		rl.report(err)
+		return
+	}
+	cutoff := time.Now().Add(-rl.maxAge)
+	dir := rl.backupDir()
+	for i, fi := range backups {
+		if (rl.maxBackups > 0 && i >= rl.maxBackups) || (rl.maxAge > 0 && fi.ModTime().Before(cutoff)) {
+			if err := os.Remove(filepath.Join(dir, fi.Name())); err != nil {
+				rl.report(err)
+			}
+		}
+	}
+}
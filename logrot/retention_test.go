@@ -0,0 +1,81 @@
+package logrot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsBackup(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"app-2026-10-16.log", true},
+		{"app-2026-10-16-3.log", true},
+		{"app-2026-10-16T14.log", true},
+		{"app-2026-10-16T14-05.log", true},
+		{"app-2026-10-16T14-05-06.log", true},
+		{"app-2026-10-16T14-05-06.123.log", true},
+		{"app-2026-10-16T14-05-06.123-12.log", true},
+		{"app.log", false},
+		{"app-notes.log", false},
+		{"app-2026-10-16.txt", false},
+		{"app-2026-10-16-.log", false},
+		{"other-2026-10-16.log", false},
+		{"app-2026-10-16.log.gz", true},
+		{"app-2026-10-16-1.log.gz", true},
+		{"app-2026-10-16.log.gz.tmp", false},
+		{"app-2026-10-16.gz", false},
+	}
+	for _, tt := range tests {
+		if got := isBackup("app.log", tt.name, ".gz"); got != tt.want {
+			t.Errorf("isBackup(%q) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := tempDir(t)
+
+	now := time.Now()
+	files := map[string]time.Duration{
+		"app-2026-10-10.log":          6 * 24 * time.Hour,
+		"app-2026-10-14.log":          2 * 24 * time.Hour,
+		"app-2026-10-15.log":          24 * time.Hour,
+		"app-2026-10-16T01-00-00.log": time.Hour,
+		"app-notes.log":               30 * 24 * time.Hour,
+	}
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-age)
+		os.Chtimes(path, mtime, mtime)
+	}
+	rl := &LogRot{name: filepath.Join(dir, "app.log"), maxBackups: 3, maxAge: 5 * 24 * time.Hour}
+	rl.prune()
+
+	fis, _ := ioutil.ReadDir(dir)
+	var got []string
+	for _, fi := range fis {
+		got = append(got, fi.Name())
+	}
+	sort.Strings(got)
+	want := "app-2026-10-14.log app-2026-10-15.log app-2026-10-16T01-00-00.log app-notes.log"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %v; want %s", got, want)
+	}
+
+	rl.maxBackups = 1
+	rl.prune()
+	fis, _ = ioutil.ReadDir(dir)
+	if len(fis) != 2 {
+		t.Errorf("got %d files; want the newest backup and app-notes.log", len(fis))
+	}
+}
@@ -0,0 +1,99 @@
+package logrot
+
+import "time"
+
+// A Schedule rotates the log file at regular wall-clock boundaries in a
+// location. The file is renamed to a backup named after the start of the
+// period it covers, e.g. app-2026-10-16.log for a daily schedule, and name
+// is reopened.
+type Schedule struct {
+	every time.Duration
+	loc   *time.Location
+}
+
+// Hourly returns a Schedule that rotates at the start of every hour in loc.
+// A nil loc means local time.
+func Hourly(loc *time.Location) Schedule {
+	return Every(time.Hour, loc)
+}
+
+// Daily returns a Schedule that rotates at midnight in loc. A nil loc
+// means local time.
+func Daily(loc *time.Location) Schedule {
+	return Every(24*time.Hour, loc)
+}
+
+// Every returns a Schedule that rotates every d, at multiples of d since
+// midnight in loc; a period that would span midnight ends there. Intervals
+// of a day or more are rounded down to whole days, each starting at
+// midnight. A nil loc means local time. A d of zero or less never rotates.
+func Every(d time.Duration, loc *time.Location) Schedule {
+	if d <= 0 {
+		return Schedule{}
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return Schedule{every: d, loc: loc}
+}
+
+// WithSchedule rotates the file on the schedule s in addition to signals.
+func WithSchedule(s Schedule) Option {
+	return func(rl *LogRot) {
+		rl.schedule = s
+	}
+}
+
+const day = 24 * time.Hour
+
+// start returns the start of the period that contains t.
+func (s Schedule) start(t time.Time) time.Time {
+	t = t.In(s.loc)
+	if s.every >= day {
+		return s.wallClock(t, 0)
+	}
+	return s.wallClock(t, sinceMidnight(t)/s.every*s.every)
+}
+
+// next returns the start of the period after the one that contains t.
+func (s Schedule) next(t time.Time) time.Time {
+	start := s.start(t)
+	if s.every >= day {
+		y, m, d := start.Date()
+		return time.Date(y, m, d+int(s.every/day), 0, 0, 0, 0, s.loc)
+	}
+	elapsed := sinceMidnight(start) + s.every
+	if elapsed >= day {
+		y, m, d := start.Date()
+		return time.Date(y, m, d+1, 0, 0, 0, 0, s.loc)
+	}
+	return s.wallClock(start, elapsed)
+}
+
+// sinceMidnight returns the wall-clock time of day of t, which differs
+// from the time elapsed since midnight on days with a DST change.
+func sinceMidnight(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// wallClock returns the time at wall-clock time of day d on the date of t.
+func (s Schedule) wallClock(t time.Time, d time.Duration) time.Time {
+	y, m, dd := t.Date()
+	return time.Date(y, m, dd, 0, 0, int(d/time.Second), int(d%time.Second), s.loc)
+}
+
+// layout returns the layout of the period start in backup names: as
+// precise as the interval needs.
+func (s Schedule) layout() string {
+	switch {
+	case s.every >= day:
+		return "2006-01-02"
+	case s.every >= time.Hour:
+		return "2006-01-02T15"
+	case s.every >= time.Minute:
+		return "2006-01-02T15-04"
+	}
+	return "2006-01-02T15-04-05"
+}
@@ -0,0 +1,69 @@
+package logrot
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cention-sany/log"
+)
+
+func TestScheduleBoundaries(t *testing.T) {
+	loc := time.FixedZone("X", 2*3600)
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04:05", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		s           Schedule
+		t           string
+		start, next string
+	}{
+		{Hourly(loc), "2026-10-16 14:25:00", "2026-10-16 14:00:00", "2026-10-16 15:00:00"},
+		{Hourly(loc), "2026-10-16 23:59:59", "2026-10-16 23:00:00", "2026-10-17 00:00:00"},
+		{Daily(loc), "2026-10-16 14:25:00", "2026-10-16 00:00:00", "2026-10-17 00:00:00"},
+		{Daily(loc), "2026-12-31 00:00:00", "2026-12-31 00:00:00", "2027-01-01 00:00:00"},
+		{Every(15*time.Minute, loc), "2026-10-16 14:25:00", "2026-10-16 14:15:00", "2026-10-16 14:30:00"},
+		{Every(7*time.Hour, loc), "2026-10-16 22:00:00", "2026-10-16 21:00:00", "2026-10-17 00:00:00"},
+		{Every(48*time.Hour, loc), "2026-10-16 14:25:00", "2026-10-16 00:00:00", "2026-10-18 00:00:00"},
+	}
+	for _, tt := range tests {
+		tm := at(tt.t)
+		if got := tt.s.start(tm); !got.Equal(at(tt.start)) {
+			t.Errorf("%v start(%s) = %v; want %s", tt.s.every, tt.t, got, tt.start)
+		}
+		if got := tt.s.next(tm); !got.Equal(at(tt.next)) {
+			t.Errorf("%v next(%s) = %v; want %s", tt.s.every, tt.t, got, tt.next)
+		}
+	}
+	// The location is honoured: midnight in loc is 22:00 UTC.
+	if got := Daily(loc).next(at("2026-10-16 12:00:00").UTC()); got.UTC().Hour() != 22 {
+		t.Errorf("Daily next in UTC is %v; want 22:00", got.UTC())
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	dir := tempDir(t)
+
+	l := log.New(nil, "", 0)
+	rl := New(filepath.Join(dir, "log.txt"), WithLoggers(l), WithSchedule(Every(200*time.Millisecond, time.UTC)))
+	defer rl.Close()
+
+	var backups []string
+	for i := 0; i < 50 && len(backups) == 0; i++ {
+		l.Println("tick")
+		time.Sleep(20 * time.Millisecond)
+		backups, _ = filepath.Glob(filepath.Join(dir, "log-*.txt"))
+	}
+	if len(backups) == 0 {
+		t.Fatal("no rotation within a second")
+	}
+	name := filepath.Base(backups[0])
+	if _, err := time.Parse("log-2006-01-02T15-04-05.txt", strings.Replace(name, "-1.txt", ".txt", 1)); err != nil {
+		t.Errorf("unexpected backup name %s: %v", name, err)
+	}
+}
@@ -0,0 +1,79 @@
+package logrot
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithWatch reopens the file when name no longer refers to it, because
+// another program renamed or removed it without sending a signal. The
+// directory of name, and with WithNaming that of the active file, is
+// watched with inotify on Linux; elsewhere, or if that fails, name is
+// checked every interval.
+func WithWatch(interval time.Duration) Option {
+	return func(rl *LogRot) {
+		rl.watchInterval = interval
+	}
+}
+
+// startWatch starts the watcher. Changes are watched from its return on.
+func (rl *LogRot) startWatch() {
+	rl.unwatch = make(chan struct{})
+	rl.watchDone = make(chan struct{})
+	dirs := []string{filepath.Dir(rl.name)}
+	if rl.naming != "" {
+		dirs = append(dirs, filepath.Dir(rl.namingLayout()))
+	}
+	events, _ := watchDirs(dirs...) // nil if unavailable.
+	go rl.watch(events, rl.unwatch)
+}
+
+// watch reopens the file when name no longer refers to it, until stop is
+// closed: on events, if any, and by polling when there are none.
+func (rl *LogRot) watch(events *dirEvents, stop <-chan struct{}) {
+	defer close(rl.watchDone)
+	if events != nil {
+		rl.readEvents(events, stop)
+	}
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	rl.poll(stop)
+}
+
+// poll checks the file every watchInterval until stop is closed.
+func (rl *LogRot) poll(stop <-chan struct{}) {
+	t := time.NewTicker(rl.watchInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			rl.checkFile()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// checkFile reopens the file if name no longer refers to it. It does
+// nothing while a failed rotation waits for its retry. name is checked
+// with rl.mu held, so that the rename of a rotation in progress is not
+// taken for a move.
+func (rl *LogRot) checkFile() {
+	rl.mu.RLock()
+	if rl.failures > 0 {
+		rl.mu.RUnlock()
+		return
+	}
+	fi, err := os.Stat(rl.name)
+	cur, cerr := rl.logFile.Stat()
+	rl.mu.RUnlock()
+	if err == nil && cerr == nil && os.SameFile(fi, cur) {
+		return
+	}
+	lg.Printf("%s moved or removed - reopening\n", rl.name)
+	rl.reopen("moved")
+}
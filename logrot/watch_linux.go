@@ -0,0 +1,92 @@
+//go:build linux
+
+package logrot
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// dirEvents reports the creation, removal and renaming of files in
+// directories.
+type dirEvents struct {
+	*os.File
+	dirs map[int32]string // directory of each watch descriptor
+}
+
+// watchDirs returns an inotify descriptor reporting changes in dirs.
+func watchDirs(dirs ...string) (*dirEvents, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	events := &dirEvents{dirs: make(map[int32]string)}
+	for _, dir := range dirs {
+		wd, err := syscall.InotifyAddWatch(fd, dir, watchMask)
+		if err != nil {
+			syscall.Close(fd)
+			return nil, os.NewSyscallError("inotify_add_watch", err)
+		}
+		events.dirs[int32(wd)] = dir
+	}
+	// A non-blocking descriptor uses the runtime poller, so closing the
+	// file interrupts Read.
+	events.File = os.NewFile(uintptr(fd), "inotify")
+	return events, nil
+}
+
+// readEvents checks the file on every event about name or, with
+// WithNaming, the active file, until stop is closed or a directory itself
+// goes away. It closes events.
+func (rl *LogRot) readEvents(events *dirEvents, stop <-chan struct{}) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+		events.Close()
+	}()
+
+	logName := filepath.Clean(rl.name)
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := events.Read(buf)
+		if err != nil {
+			return
+		}
+		rl.mu.RLock()
+		path := rl.path // follows the switches of WithNaming.
+		rl.mu.RUnlock()
+		check := false
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			name := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+			off += syscall.SizeofInotifyEvent + int(ev.Len)
+			if ev.Mask&syscall.IN_IGNORED != 0 {
+				return // the directory was removed; poll instead.
+			}
+			if p := filepath.Join(events.dirs[ev.Wd], cstring(name)); p == logName || p == path {
+				check = true
+			}
+		}
+		if check {
+			rl.checkFile()
+		}
+	}
+}
+
+// cstring returns b up to its first NUL byte.
+func cstring(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
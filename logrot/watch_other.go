@@ -0,0 +1,14 @@
+//go:build !linux
+
+package logrot
+
+import "errors"
+
+type dirEvents struct{}
+
+// watchDirs fails: only polling is available.
+func watchDirs(dirs ...string) (*dirEvents, error) {
+	return nil, errors.New("logrot: file events are not supported on this platform")
+}
+
+func (rl *LogRot) readEvents(events *dirEvents, stop <-chan struct{}) {}
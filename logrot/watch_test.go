@@ -0,0 +1,124 @@
+package logrot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cention-sany/log"
+)
+
+// waitReopen waits until rl writes to a file other than old.
+func waitReopen(t *testing.T, rl *LogRot, old *os.File) {
+	for i := 0; i < 200; i++ {
+		rl.mu.Lock()
+		cur := rl.logFile
+		rl.mu.Unlock()
+		if cur != old {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("file not reopened")
+}
+
+func testWatch(t *testing.T, poll bool) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	opts := []Option{WithSignals()}
+	if !poll {
+		opts = append(opts, WithWatch(time.Hour))
+	}
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, append(opts, WithLoggers(l))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+	if poll {
+		rl.watchInterval = 10 * time.Millisecond
+		stop, done := make(chan struct{}), make(chan struct{})
+		defer func() { close(stop); <-done }()
+		go func() { rl.poll(stop); close(done) }()
+	}
+
+	for _, move := range []func() error{
+		func() error { return os.Rename(name, name+".old") },
+		func() error { return os.Remove(name) },
+	} {
+		rl.mu.Lock()
+		old := rl.logFile
+		rl.mu.Unlock()
+		if err := move(); err != nil {
+			t.Fatal(err)
+		}
+		waitReopen(t, rl, old)
+		l.Println("after")
+		if got := readFile(t, name); got != "after\n" {
+			t.Errorf("got %q; want the line logged after reopening", got)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	testWatch(t, false)
+}
+
+func TestWatchPoll(t *testing.T) {
+	testWatch(t, true)
+}
+
+func TestWatchNaming(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "app.log")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithNaming("app-20060102.log"), WithWatch(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	rl.mu.Lock()
+	old, path := rl.logFile, rl.path
+	rl.mu.Unlock()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitReopen(t, rl, old)
+	l.Println("after")
+	if got := readFile(t, name); got != "after\n" {
+		t.Errorf("got %q; want the line logged after reopening", got)
+	}
+}
+
+func TestWatchSizeRotation(t *testing.T) {
+	dir := tempDir(t)
+	name := filepath.Join(dir, "log.txt")
+
+	l := log.New(nil, "", 0)
+	rl, err := Open(name, WithLoggers(l), WithSignals(), WithMaxSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	checked := make(chan struct{})
+	defer func() { renameHook = nil }()
+	renameHook = func() {
+		renameHook = nil
+		go func() {
+			rl.checkFile()
+			close(checked)
+		}()
+		time.Sleep(20 * time.Millisecond) // name is missing meanwhile.
+	}
+	l.Println("before")
+	l.Println("rotated") // takes the file past 10 bytes.
+	<-checked
+	if got := readFile(t, name); got != "rotated\n" {
+		t.Errorf("got %q; want only the line logged after the rotation", got)
+	}
+}
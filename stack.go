@@ -0,0 +1,81 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// A StackTrace selects the goroutine stacks added to an entry.
+type StackTrace int32
+
+const (
+	StackNone    StackTrace = iota // no stack trace
+	StackCurrent                   // the stack of the logging goroutine, from its caller up
+	StackAll                       // the stacks of all goroutines, as by runtime.Stack
+)
+
+// StackTrace returns the stack trace added to Fatal and Panic entries of the logger.
+func (l *Logger) StackTrace() StackTrace {
+	return StackTrace(atomic.LoadInt32(&l.stack))
+}
+
+// SetStackTrace sets the stack trace added to entries written by the Fatal
+// and Panic methods of the logger. It is safe to call while other
+// goroutines are logging.
+func (l *Logger) SetStackTrace(st StackTrace) {
+	atomic.StoreInt32(&l.stack, int32(st))
+}
+
+// PrintStack writes an entry with the stack of the calling goroutine. The
+// stack starts skip frames above the caller of PrintStack, so 0 starts at
+// the caller itself; the header, if any, is that of the same frame.
+func (l *Logger) PrintStack(skip int) {
+	l.output(skip+2, NoLevel, "stack trace", StackCurrent)
+}
+
+// captureStack returns a stack trace of the kind st. For StackCurrent it
+// starts skip frames up, counted as by runtime.Caller from the caller of
+// captureStack, and lists one function per frame, inlined ones included,
+// in the layout of runtime.Stack:
+//
+//	pkg.function
+//		/path/to/file.go:23
+func captureStack(skip int, st StackTrace) []byte {
+	if st == StackAll {
+		buf := make([]byte, 8192)
+		for {
+			n := runtime.Stack(buf, true)
+			if n < len(buf) {
+				return buf[:n]
+			}
+			buf = make([]byte, 2*len(buf))
+		}
+	}
+	pcs := make([]uintptr, 32)
+	for {
+		n := runtime.Callers(skip+2, pcs) // +2 for runtime.Callers and this frame.
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+	var buf []byte
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		buf = append(buf, f.Function...)
+		buf = append(buf, "\n\t"...)
+		buf = append(buf, f.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(f.Line), 10)
+		buf = append(buf, '\n')
+		if !more {
+			return buf
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintStack(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	l.PrintStack(0)
+	got := b.String()
+	want := "stack trace\ngithub.com/cention-sany/log.TestPrintStack\n\t"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("got %q; want prefix %q", got, want)
+	}
+	if !strings.Contains(got, "stack_test.go:") || strings.Contains(got, "(*Logger).PrintStack") {
+		t.Errorf("unexpected stack %q", got)
+	}
+}
+
+func TestPanicStack(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, "", 0)
+	l.SetStackTrace(StackCurrent)
+	func() {
+		defer func() { recover() }()
+		l.Panicf("boom %d", 1)
+	}()
+	want := "boom 1\ngithub.com/cention-sany/log.TestPanicStack.func1\n\t"
+	if got := b.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("got %q; want prefix %q", got, want)
+	}
+
+	b.Reset()
+	l.SetStackTrace(StackAll)
+	func() {
+		defer func() { recover() }()
+		l.Panic("all")
+	}()
+	if got := b.String(); !strings.HasPrefix(got, "all\ngoroutine ") {
+		t.Errorf("got %q; want all goroutines", got)
+	}
+
+	b.Reset()
+	l.Print("no stack")
+	if got := b.String(); got != "no stack\n" {
+		t.Errorf("got %q; want no stack for Print", got)
+	}
+}